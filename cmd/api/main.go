@@ -3,11 +3,14 @@ package main
 import (
 	"appointment-booking/internal/config"
 	"appointment-booking/internal/domain"
+	"appointment-booking/internal/event"
 	"appointment-booking/internal/handler"
 	"appointment-booking/internal/middleware"
 	"appointment-booking/internal/repository"
 	"appointment-booking/internal/service"
 	"appointment-booking/internal/websocket"
+	"appointment-booking/pkg/notifier"
+	"appointment-booking/pkg/utils"
 
 	"context"
 	"log"
@@ -16,6 +19,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Embed the IANA database so zone lookups work in minimal containers
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,53 +32,125 @@ func main() {
 
 	db := config.ConnectDB(cfg)
 
-	if err := db.AutoMigrate(
-		&domain.User{},
-		&domain.Appointment{},
-		&domain.Availability{},
-	); err != nil {
+	if err := config.Migrate(db); err != nil {
 		log.Fatal("Migration failed:", err)
 	}
 	log.Println("Database migration completed")
 
-	wsHandler := websocket.NewHandler()
-	go wsHandler.Run()
+	utils.UseKeys(config.LoadTokenKeys(cfg))
 
 	// --------------------
 	// Redis
 	// --------------------
 	redisClient := config.ConnectRedis(cfg)
 
+	revocationRepo := repository.NewRevocationRepository(redisClient)
+	wsTicketRepo := repository.NewWSTicketRepository(redisClient)
+
+	// Events fan out to the other API instances through Redis pub/sub
+	wsHandler := websocket.NewHandler(cfg.WSAllowedOrigins, redisClient, revocationRepo, wsTicketRepo)
+	go wsHandler.Run()
+
 	// --------------------
 	// Repositories
 	// --------------------
 	userRepo := repository.NewUserRepository(db)
 	apptRepo := repository.NewAppointmentRepository(db)
 	availRepo := repository.NewAvailabilityRepository(db)
+	serviceRepo := repository.NewServiceRepository(db)
+	holdRepo := repository.NewHoldRepository(redisClient)
+	historyRepo := repository.NewHistoryRepository(db)
+	policyRepo := repository.NewCancellationPolicyRepository(db)
+	waitlistRepo := repository.NewWaitlistRepository(db)
+	reminderRepo := repository.NewReminderRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	prefRepo := repository.NewNotificationPreferenceRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	applicationRepo := repository.NewProviderApplicationRepository(db)
+	accountTokenRepo := repository.NewAccountTokenRepository(db)
 
 	// --------------------
 	// Services
 	// --------------------
-	authService := service.NewAuthService(userRepo)
+	// Side effects of bookings, availability edits and sign-ups subscribe to the bus below
+	bus := event.NewBus()
+
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, revocationRepo, bus, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+
+	// Fall back to logging emails when no mail server is configured
+	var emailSender notifier.Notifier = notifier.NewLogNotifier()
+	if cfg.SMTPHost != "" {
+		emailSender = notifier.NewSMTPNotifier(notifier.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+	}
 
-	notifyService := service.NewNotificationService()
-	apptService := service.NewAppointmentService(
-		apptRepo,
-		notifyService,
-		wsHandler,
-		redisClient,
-	)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
 
-	availService := service.NewAvailabilityService(availRepo, apptRepo, redisClient)
+	notifyService := service.NewNotificationService(notificationRepo, prefRepo, userRepo, map[domain.NotificationChannel]notifier.Notifier{
+		domain.ChannelEmail:   emailSender,
+		domain.ChannelInApp:   service.NewInAppNotifier(wsHandler),
+		domain.ChannelWebhook: notifier.NewWebhookNotifier(),
+	}, cfg.NotificationMaxAttempts)
+	notifyService.StartWorker(workerCtx)
+	go wsHandler.Relay(workerCtx)
+
+	slotFeed := service.NewSlotFeed(wsHandler, userRepo)
+
+	reminderService := service.NewReminderService(reminderRepo, apptRepo, notifyService, cfg.ReminderOffsets)
+	reminderService.StartWorker(workerCtx, time.Minute)
+
+	apptService := service.NewAppointmentService(apptRepo, serviceRepo, holdRepo, historyRepo, policyRepo, bus)
+	availService := service.NewAvailabilityService(availRepo, apptRepo, serviceRepo, userRepo, holdRepo, bus, redisClient)
+	holdService := service.NewHoldService(holdRepo, apptRepo, apptService, cfg.SlotHoldTTL, cfg.MaxHoldsPerCustomer)
+
+	waitlistService := service.NewWaitlistService(waitlistRepo, holdRepo, apptRepo, serviceRepo, userRepo, holdService, availService, notifyService, bus, cfg.WaitlistOfferTTL)
+	waitlistService.StartExpiryWorker(workerCtx, 30*time.Second)
+
+	catalogService := service.NewCatalogService(serviceRepo, userRepo)
+	userService := service.NewUserService(userRepo, bus)
 
 	reportService := service.NewReportService(apptRepo)
+	auditService := service.NewAuditService(historyRepo)
+
+	// Inside the booking transaction
+	auditService.Subscribe(bus)
+	reminderService.Subscribe(bus)
+	notifyService.Subscribe(bus)
+	// After commit
+	service.NewSlotCache(redisClient).Subscribe(bus)
+	slotFeed.Subscribe(bus)
+	service.NewLiveUpdates(wsHandler).Subscribe(bus)
+	waitlistService.Subscribe(bus)
+	service.NewEventWebhooks(cfg.EventWebhookURLs).Subscribe(bus)
+
+	accountService := service.NewAccountService(userRepo, accountTokenRepo, notifyService, authService, cfg.AppBaseURL, cfg.EmailVerificationTTL, cfg.PasswordResetTTL)
+	accountService.Subscribe(bus)
+
+	policyService := service.NewCancellationPolicyService(policyRepo)
+	applicationService := service.NewProviderApplicationService(applicationRepo, userRepo)
+	userAdminService := service.NewUserAdminService(userRepo, authService)
 	// --------------------
 	// Handlers
 	// --------------------
 	authHandler := handler.NewAuthHandler(authService)
+	accountHandler := handler.NewAccountHandler(accountService)
 	apptHandler := handler.NewAppointmentHandler(apptService)
 	availHandler := handler.NewAvailabilityHandler(availService)
-	adminHandler := handler.NewAdminHandler(reportService)
+	adminHandler := handler.NewAdminHandler(reportService, auditService)
+	catalogHandler := handler.NewCatalogHandler(catalogService)
+	userHandler := handler.NewUserHandler(userService)
+	holdHandler := handler.NewHoldHandler(holdService)
+	policyHandler := handler.NewCancellationPolicyHandler(policyService)
+	waitlistHandler := handler.NewWaitlistHandler(waitlistService)
+	notificationHandler := handler.NewNotificationHandler(notifyService)
+	applicationHandler := handler.NewProviderApplicationHandler(applicationService)
+	userAdminHandler := handler.NewUserAdminHandler(userAdminService)
 
 	// --------------------
 	// Router
@@ -92,40 +168,113 @@ func main() {
 		})
 	})
 
+	// Public keys for other services verifying our tokens
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	router.POST("/auth/register", authHandler.Register)
 	router.POST("/auth/login", authHandler.Login)
+	router.POST("/auth/refresh", authHandler.Refresh)
+	router.POST("/auth/logout", authHandler.Logout)
+	// Each request can send an email, so limit how often one client may ask
+	router.POST("/auth/forgot-password", middleware.RateLimitMiddleware(redisClient), accountHandler.ForgotPassword)
+	router.POST("/auth/reset-password", accountHandler.ResetPassword)
+	router.POST("/auth/verify-email", accountHandler.VerifyEmail)
 
 	router.GET("/providers/:providerID/slots", availHandler.GetSlots)
+	router.GET("/providers/:providerID/availability", availHandler.GetSchedule)
+	router.GET("/providers/:providerID/cancellation-policy", policyHandler.Get)
+
+	router.GET("/services", catalogHandler.List)
+	router.GET("/services/:id", catalogHandler.Get)
 
 	// --------------------
 	// Protected routes
 	// --------------------
 	protected := router.Group("/api")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(middleware.AuthMiddleware(revocationRepo))
 	{
 		protected.GET("/me", func(c *gin.Context) {
 			userID, _ := c.Get("userID")
-			role, _ := c.Get("role")
+			roles := c.MustGet("roles").(domain.UserRoles)
 
 			c.JSON(http.StatusOK, gin.H{
-				"user_id": userID,
-				"role":    role,
-				"message": "You are authenticated!",
+				"user_id":        userID,
+				"roles":          roles,
+				"permissions":    roles.Permissions(),
+				"email_verified": c.GetBool("emailVerified"),
+				"message":        "You are authenticated!",
 			})
 		})
 
-		protected.POST("/appointments", apptHandler.Create)
+		protected.POST("/me/logout-all", authHandler.LogoutAll)
+		// Single-use ticket for GET /ws?ticket=, so access tokens stay out of URLs
+		protected.POST("/ws/ticket", wsHandler.IssueTicket)
+		protected.POST("/me/verify-email", accountHandler.ResendVerification)
+		protected.PUT("/me/settings", userHandler.UpdateSettings)
+		protected.GET("/me/notifications", notificationHandler.GetPreferences)
+		protected.PUT("/me/notifications", notificationHandler.UpdatePreferences)
+
+		protected.GET("/appointments", apptHandler.List)
+		protected.GET("/appointments/:id", apptHandler.Get)
+		// Unverified accounts can look around but not take up slots
+		verified := middleware.RequireVerifiedEmail()
+
+		protected.POST("/appointments", verified, apptHandler.Create)
 		protected.PUT("/appointments/:id/cancel", apptHandler.Cancel)
 		protected.PUT("/appointments/:id/reschedule", apptHandler.Reschedule)
+		// Providers act on their own calendar; the service also lets appointments:manage_all through
+		protected.PUT("/appointments/:id/confirm", apptHandler.Confirm)
+		protected.PUT("/appointments/:id/decline", apptHandler.Decline)
+		protected.PUT("/appointments/:id/no-show", apptHandler.NoShow)
+		protected.PUT("/appointments/:id/complete", apptHandler.Complete)
+		protected.GET("/appointments/:id/history", apptHandler.History)
+
+		protected.POST("/holds", verified, holdHandler.Create)
+		protected.POST("/holds/:id/confirm", verified, holdHandler.Confirm)
+		protected.DELETE("/holds/:id", holdHandler.Release)
+
+		protected.POST("/waitlist", verified, waitlistHandler.Join)
+		protected.GET("/waitlist", waitlistHandler.List)
+		protected.DELETE("/waitlist/:id", waitlistHandler.Leave)
+		protected.POST("/waitlist/:id/claim", verified, waitlistHandler.Claim)
+
+		schedule := middleware.RequirePermission(domain.PermManageSchedule)
+		protected.PUT("/cancellation-policy", schedule, policyHandler.Set)
 
-		protected.POST("/availability", availHandler.SetAvailability)
+		protected.POST("/provider-application", applicationHandler.Apply)
+		protected.GET("/provider-application", applicationHandler.Mine)
+
+		protected.POST("/availability", schedule, availHandler.SetAvailability)
+		protected.DELETE("/availability/:id", schedule, availHandler.DeleteAvailability)
+		protected.POST("/availability/overrides", schedule, availHandler.AddOverride)
+		protected.DELETE("/availability/overrides/:id", schedule, availHandler.DeleteOverride)
 	}
 
 	adminGroup := router.Group("/admin")
-	adminGroup.Use(middleware.AuthMiddleware())
-	adminGroup.Use(middleware.RequireRole("admin"))
+	adminGroup.Use(middleware.AuthMiddleware(revocationRepo))
 	{
-		adminGroup.GET("/dashboard", adminHandler.GetDashboard)
+		adminGroup.GET("/dashboard", middleware.RequirePermission(domain.PermViewReports), adminHandler.GetDashboard)
+		adminGroup.GET("/audit", middleware.RequirePermission(domain.PermViewAudit), adminHandler.SearchAudit)
+
+		notifications := middleware.RequirePermission(domain.PermManageNotifications)
+		adminGroup.GET("/notifications", notifications, notificationHandler.List)
+		adminGroup.POST("/notifications/:id/replay", notifications, notificationHandler.Replay)
+
+		users := middleware.RequirePermission(domain.PermManageUsers)
+		adminGroup.GET("/provider-applications", users, applicationHandler.List)
+		adminGroup.POST("/provider-applications/:id/approve", users, applicationHandler.Approve)
+		adminGroup.POST("/provider-applications/:id/reject", users, applicationHandler.Reject)
+
+		adminGroup.GET("/users", users, userAdminHandler.List)
+		adminGroup.PUT("/users/:id/roles", users, userAdminHandler.SetRoles)
+		adminGroup.POST("/users/:id/suspend", users, userAdminHandler.Suspend)
+		adminGroup.POST("/users/:id/reactivate", users, userAdminHandler.Reactivate)
+		adminGroup.DELETE("/users/:id", users, userAdminHandler.Delete)
+
+		services := middleware.RequirePermission(domain.PermManageServices)
+		adminGroup.POST("/services", services, catalogHandler.Create)
+		adminGroup.PUT("/services/:id", services, catalogHandler.Update)
+		adminGroup.DELETE("/services/:id", services, catalogHandler.Delete)
 	}
 
 	// --------------------
@@ -159,5 +308,12 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Stop the workers and let the notification batch in flight finish; anything
+	// still queued stays in the outbox for the next start
+	stopWorkers()
+	if err := notifyService.Wait(ctx); err != nil {
+		log.Printf("Notification worker did not stop in time: %v", err)
+	}
+
 	log.Println("Server exited cleanly")
 }
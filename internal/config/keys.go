@@ -0,0 +1,46 @@
+package config
+
+import (
+	"appointment-booking/pkg/utils"
+	"log"
+	"strings"
+)
+
+// The secret that used to ship in the committed .env; anyone can forge tokens with it
+const leakedJWTSecret = "super_secret_key_change_me_in_prod"
+
+// LoadTokenKeys builds the JWT key set from JWT_SECRET and the PEM files in JWT_KEYS
+func LoadTokenKeys(cfg *Config) *utils.KeySet {
+	var keys []*utils.Key
+
+	if cfg.JWTSecret != "" {
+		if cfg.JWTSecret == leakedJWTSecret {
+			log.Fatal("JWT_SECRET is the published example value; generate a new one, e.g. with `openssl rand -base64 48`")
+		}
+		key, err := utils.HMACKey(cfg.JWTSecretKeyID, []byte(cfg.JWTSecret))
+		if err != nil {
+			log.Fatal("Invalid JWT secret: ", err)
+		}
+		keys = append(keys, key)
+	}
+
+	for _, entry := range cfg.JWTKeyFiles {
+		id, path, ok := strings.Cut(entry, "=")
+		if !ok || id == "" || path == "" {
+			log.Fatalf("Invalid JWT_KEYS entry %q (use kid=path)", entry)
+		}
+		key, err := utils.LoadPEMKey(id, path)
+		if err != nil {
+			log.Fatal("Failed to load JWT key: ", err)
+		}
+		keys = append(keys, key)
+	}
+
+	ks, err := utils.NewKeySet(cfg.JWTSigningKeyID, keys...)
+	if err != nil {
+		log.Fatal("Invalid JWT key configuration: ", err)
+	}
+
+	log.Printf("JWT keys loaded, signing with %q", cfg.JWTSigningKeyID)
+	return ks
+}
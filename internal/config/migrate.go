@@ -0,0 +1,100 @@
+package config
+
+import (
+	"appointment-booking/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// Migrate creates/updates the schema and applies data fixes that AutoMigrate can't express
+func Migrate(db *gorm.DB) error {
+	// Checked before AutoMigrate adds the column, see the backfill below
+	addingEmailVerification := db.Migrator().HasTable("users") && !db.Migrator().HasColumn("users", "email_verified_at")
+
+	if err := db.AutoMigrate(
+		&domain.User{},
+		&domain.Service{},
+		&domain.Appointment{},
+		&domain.Availability{},
+		&domain.AvailabilityOverride{},
+		&domain.AppointmentHistory{},
+		&domain.CancellationPolicy{},
+		&domain.WaitlistEntry{},
+		&domain.AppointmentReminder{},
+		&domain.Notification{},
+		&domain.NotificationPreference{},
+		&domain.RefreshToken{},
+		&domain.ProviderApplication{},
+		&domain.AccountToken{},
+	); err != nil {
+		return err
+	}
+
+	// Accounts from before email verification keep booking; only new sign-ups must verify
+	if addingEmailVerification {
+		if err := db.Exec(`UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL`).Error; err != nil {
+			return err
+		}
+	}
+
+	// Users from before multiple roles carry their single role in the old column
+	if db.Migrator().HasColumn("users", "role") {
+		if err := db.Exec(`UPDATE users SET roles = jsonb_build_array(role) WHERE role IS NOT NULL AND role <> ''`).Error; err != nil {
+			return err
+		}
+		if err := db.Migrator().DropColumn("users", "role"); err != nil {
+			return err
+		}
+	}
+
+	// Appointments created before buffers existed occupy exactly their own time range
+	if err := db.Exec(`
+		UPDATE appointments
+		SET block_start = start_time, block_end = end_time
+		WHERE block_start IS NULL OR block_end IS NULL
+	`).Error; err != nil {
+		return err
+	}
+
+	// Database-level double-booking guard: no two active appointments of the same
+	// provider may have overlapping blocked ranges. btree_gist lets the gist index
+	// combine the uuid equality with the range overlap.
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS btree_gist`).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'appointments_no_overlap') THEN
+				ALTER TABLE appointments
+				ADD CONSTRAINT appointments_no_overlap
+				EXCLUDE USING gist (
+					provider_id WITH =,
+					tstzrange(block_start, block_end, '[)') WITH &&
+				) WHERE (status IN ('PENDING', 'CONFIRMED'));
+			END IF;
+		END
+		$$;
+	`).Error; err != nil {
+		return err
+	}
+
+	// The audit trail is append-only, even for direct SQL access
+	if err := db.Exec(`
+		CREATE OR REPLACE FUNCTION reject_history_change() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'appointment_history is append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+	`).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(`DROP TRIGGER IF EXISTS appointment_history_append_only ON appointment_history`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`
+		CREATE TRIGGER appointment_history_append_only
+		BEFORE UPDATE OR DELETE ON appointment_history
+		FOR EACH ROW EXECUTE FUNCTION reject_history_change()
+	`).Error
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AccountTokenPurpose string
+
+const (
+	PurposeVerifyEmail   AccountTokenPurpose = "verify_email"
+	PurposePasswordReset AccountTokenPurpose = "password_reset"
+)
+
+// AccountToken backs one emailed verification or password reset link. The link itself
+// is a signed token naming this row; the row makes it single-use and lets newer links
+// (or a completed reset) invalidate older ones.
+type AccountToken struct {
+	ID        uuid.UUID           `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID           `gorm:"type:uuid;not null;index" json:"user_id"`
+	Purpose   AccountTokenPurpose `gorm:"type:varchar(20);not null" json:"purpose"`
+	ExpiresAt time.Time           `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time          `json:"used_at,omitempty"` // Set when redeemed or superseded
+	CreatedAt time.Time           `json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "github.com/google/uuid"
+
+// Actor is the authenticated user performing an action, as taken from the JWT
+type Actor struct {
+	ID    uuid.UUID
+	Roles UserRoles
+}
+
+func (a Actor) Can(permission Permission) bool {
+	return a.Roles.Can(permission)
+}
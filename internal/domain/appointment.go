@@ -13,8 +13,41 @@ const (
 	StatusConfirmed AppointmentStatus = "CONFIRMED"
 	StatusCancelled AppointmentStatus = "CANCELLED"
 	StatusCompleted AppointmentStatus = "COMPLETED"
+	StatusNoShow    AppointmentStatus = "NO_SHOW"
 )
 
+// appointmentTransitions is the single source of truth for status changes.
+// CONFIRMED -> PENDING happens when a confirmed appointment is rescheduled and
+// needs the provider to confirm the new time.
+var appointmentTransitions = map[AppointmentStatus][]AppointmentStatus{
+	StatusPending:   {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusPending, StatusCancelled, StatusCompleted, StatusNoShow},
+	StatusCancelled: {},
+	StatusCompleted: {},
+	StatusNoShow:    {},
+}
+
+// CanTransitionTo reports whether the transition table allows s -> next
+func (s AppointmentStatus) CanTransitionTo(next AppointmentStatus) bool {
+	for _, allowed := range appointmentTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid reports whether s is a known status
+func (s AppointmentStatus) IsValid() bool {
+	_, ok := appointmentTransitions[s]
+	return ok
+}
+
+// IsActive reports whether the appointment still occupies the provider's calendar
+func (s AppointmentStatus) IsActive() bool {
+	return s == StatusPending || s == StatusConfirmed
+}
+
 type Appointment struct {
 	ID uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 
@@ -25,13 +58,27 @@ type Appointment struct {
 	ProviderID uuid.UUID `gorm:"type:uuid;not null;index"`
 	Provider   User      `gorm:"foreignKey:ProviderID"` // Relation
 
+	ServiceID *uuid.UUID `gorm:"type:uuid;index"` // Nullable for appointments booked before the catalog existed
+	Service   *Service   `gorm:"foreignKey:ServiceID"`
+
 	// Service Details
-	ServiceType string    `gorm:"type:varchar(50);not null"` // e.g., "Haircut", "Consulting"
+	ServiceType string    `gorm:"type:varchar(100);not null"` // Snapshot of the service name, e.g., "Haircut"
 	StartTime   time.Time `gorm:"not null;index"`
 	EndTime     time.Time `gorm:"not null"`
 
+	// Time the provider is occupied, i.e. StartTime/EndTime widened by the service buffers
+	BlockStart time.Time `gorm:"index"`
+	BlockEnd   time.Time
+
 	Status AppointmentStatus `gorm:"type:varchar(20);default:'PENDING'"`
 
+	// Cancellation details, set when Status becomes CANCELLED
+	CancelledAt        *time.Time
+	CancelledBy        UserRole           `gorm:"type:varchar(20)"`
+	CancellationReason CancellationReason `gorm:"type:varchar(30)"`
+	CancellationNote   string             `gorm:"type:text"`
+	LateCancellation   bool               `gorm:"not null;default:false"`
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
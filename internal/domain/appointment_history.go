@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HistoryAction string
+
+const (
+	ActionCreated       HistoryAction = "CREATED"
+	ActionCancelled     HistoryAction = "CANCELLED"
+	ActionRescheduled   HistoryAction = "RESCHEDULED"
+	ActionStatusChanged HistoryAction = "STATUS_CHANGED"
+)
+
+// AppointmentHistory is one append-only audit entry. Rows are never updated or
+// deleted; a database trigger rejects both.
+type AppointmentHistory struct {
+	ID            uuid.UUID     `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	AppointmentID uuid.UUID     `gorm:"type:uuid;not null;index" json:"appointment_id"`
+	ActorID       uuid.UUID     `gorm:"type:uuid;not null;index" json:"actor_id"`
+	ActorRole     UserRole      `gorm:"type:varchar(20);not null" json:"actor_role"`
+	Action        HistoryAction `gorm:"type:varchar(30);not null;index" json:"action"`
+
+	OldStatus    AppointmentStatus `gorm:"type:varchar(20)" json:"old_status,omitempty"`
+	NewStatus    AppointmentStatus `gorm:"type:varchar(20)" json:"new_status,omitempty"`
+	OldStartTime *time.Time        `json:"old_start_time,omitempty"`
+	NewStartTime *time.Time        `json:"new_start_time,omitempty"`
+	Reason       string            `gorm:"type:text" json:"reason,omitempty"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+func (AppointmentHistory) TableName() string {
+	return "appointment_history"
+}
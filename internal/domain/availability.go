@@ -6,6 +6,8 @@ import (
 	"github.com/google/uuid"
 )
 
+// Availability is a recurring weekly working window. A provider may have
+// several windows on the same DayOfWeek (e.g. 09:00-12:00 and 13:00-17:00).
 type Availability struct {
 	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	ProviderID uuid.UUID `gorm:"type:uuid;not null;index"`
@@ -14,3 +16,28 @@ type Availability struct {
 	EndTime    string    `gorm:"type:varchar(5);not null"`
 	CreatedAt  time.Time
 }
+
+type OverrideType string
+
+const (
+	OverrideOpen    OverrideType = "OPEN"    // Extra working hours on this date
+	OverrideClosed  OverrideType = "CLOSED"  // Closed for the whole day, or only StartTime-EndTime if set
+	OverrideHoliday OverrideType = "HOLIDAY" // Closed for the whole day
+)
+
+// AvailabilityOverride is a date-specific exception to the weekly schedule.
+type AvailabilityOverride struct {
+	ID         uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ProviderID uuid.UUID    `gorm:"type:uuid;not null;index:idx_override_provider_date"`
+	Date       time.Time    `gorm:"type:date;not null;index:idx_override_provider_date"`
+	Type       OverrideType `gorm:"type:varchar(20);not null"`
+	StartTime  string       `gorm:"type:varchar(5)"` // Empty for full-day closures
+	EndTime    string       `gorm:"type:varchar(5)"`
+	Reason     string       `gorm:"type:varchar(255)"`
+	CreatedAt  time.Time
+}
+
+// IsFullDay reports whether the override covers the entire date.
+func (o *AvailabilityOverride) IsFullDay() bool {
+	return o.Type == OverrideHoliday || (o.Type == OverrideClosed && o.StartTime == "" && o.EndTime == "")
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CancellationReason string
+
+const (
+	ReasonScheduleConflict    CancellationReason = "SCHEDULE_CONFLICT"
+	ReasonIllness             CancellationReason = "ILLNESS"
+	ReasonEmergency           CancellationReason = "EMERGENCY"
+	ReasonNoLongerNeeded      CancellationReason = "NO_LONGER_NEEDED"
+	ReasonProviderUnavailable CancellationReason = "PROVIDER_UNAVAILABLE"
+	ReasonOther               CancellationReason = "OTHER"
+)
+
+var cancellationReasons = map[CancellationReason]bool{
+	ReasonScheduleConflict:    true,
+	ReasonIllness:             true,
+	ReasonEmergency:           true,
+	ReasonNoLongerNeeded:      true,
+	ReasonProviderUnavailable: true,
+	ReasonOther:               true,
+}
+
+func (r CancellationReason) IsValid() bool {
+	return cancellationReasons[r]
+}
+
+// CancellationRule applies to one side (customer or provider) of a cancellation
+type CancellationRule struct {
+	MinNoticeMinutes int  `gorm:"not null;default:0" json:"min_notice_minutes"`
+	AllowLate        bool `gorm:"not null" json:"allow_late"` // false: reject late cancellations, true: allow but flag them
+	RequireReason    bool `gorm:"not null;default:false" json:"require_reason"`
+}
+
+func (r CancellationRule) MinNotice() time.Duration {
+	return time.Duration(r.MinNoticeMinutes) * time.Minute
+}
+
+// CancellationPolicy is a provider's rule set. Providers without one fall back to
+// DefaultCancellationPolicy, which allows any cancellation.
+type CancellationPolicy struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ProviderID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"provider_id"`
+
+	Customer CancellationRule `gorm:"embedded;embeddedPrefix:customer_" json:"customer"`
+	Provider CancellationRule `gorm:"embedded;embeddedPrefix:provider_" json:"provider"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func DefaultCancellationPolicy(providerID uuid.UUID) *CancellationPolicy {
+	return &CancellationPolicy{
+		ProviderID: providerID,
+		Customer:   CancellationRule{AllowLate: true},
+		Provider:   CancellationRule{AllowLate: true},
+	}
+}
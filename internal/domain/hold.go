@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SlotHold temporarily reserves a slot for one customer while they finish checkout.
+// Holds live in Redis only and disappear on their own at ExpiresAt.
+type SlotHold struct {
+	ID         uuid.UUID `json:"id"`
+	CustomerID uuid.UUID `json:"customer_id"`
+	ProviderID uuid.UUID `json:"provider_id"`
+	ServiceID  uuid.UUID `json:"service_id"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	BlockStart time.Time `json:"block_start"`
+	BlockEnd   time.Time `json:"block_end"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "PENDING" // Waiting for (another) delivery attempt
+	NotificationSent    NotificationStatus = "SENT"
+	NotificationDead    NotificationStatus = "DEAD"    // Gave up; an admin can replay it
+	NotificationSkipped NotificationStatus = "SKIPPED" // User turned the channel off before it went out
+)
+
+// Notification is an outbox row. It is written in the same transaction as the change
+// it announces and delivered later by the notification worker.
+type Notification struct {
+	ID            uuid.UUID           `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID        uuid.UUID           `gorm:"type:uuid;not null;index" json:"user_id"`
+	Event         string              `gorm:"type:varchar(50);not null" json:"event"`
+	Channel       NotificationChannel `gorm:"type:varchar(20);not null;default:'email'" json:"channel"`
+	Payload       json.RawMessage     `gorm:"type:jsonb;not null" json:"payload"`
+	Status        NotificationStatus  `gorm:"type:varchar(20);not null;default:'PENDING';index:idx_notifications_due" json:"status"`
+	Attempts      int                 `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time           `gorm:"not null;index:idx_notifications_due" json:"next_attempt_at"`
+	LastError     string              `gorm:"type:text" json:"last_error,omitempty"`
+	SentAt        *time.Time          `json:"sent_at,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+type NotificationChannel string
+
+const (
+	ChannelEmail   NotificationChannel = "email"
+	ChannelInApp   NotificationChannel = "in_app" // WebSocket push
+	ChannelWebhook NotificationChannel = "webhook"
+)
+
+func (c NotificationChannel) IsValid() bool {
+	switch c {
+	case ChannelEmail, ChannelInApp, ChannelWebhook:
+		return true
+	}
+	return false
+}
+
+// DefaultChannels is what a user gets for an event they haven't configured
+var DefaultChannels = []NotificationChannel{ChannelEmail, ChannelInApp}
+
+// EventChannels maps an event name to the channels it goes out on. An event mapped to
+// an empty list is muted; an event that is missing uses DefaultChannels.
+type EventChannels map[string][]NotificationChannel
+
+func (e EventChannels) Value() (driver.Value, error) {
+	if e == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(e)
+	return string(b), err
+}
+
+func (e *EventChannels) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case nil:
+		*e = EventChannels{}
+		return nil
+	default:
+		return errors.New("unsupported type for EventChannels")
+	}
+	return json.Unmarshal(b, e)
+}
+
+// NotificationPreference holds a user's notification settings
+type NotificationPreference struct {
+	ID         uuid.UUID     `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"-"`
+	UserID     uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Events     EventChannels `gorm:"type:jsonb;not null;default:'{}'" json:"events"`
+	WebhookURL string        `gorm:"type:varchar(500)" json:"webhook_url,omitempty"`
+
+	// "22:00"-"07:00" in the user's own time zone; empty means no quiet hours
+	QuietHoursStart string `gorm:"type:varchar(5)" json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd   string `gorm:"type:varchar(5)" json:"quiet_hours_end,omitempty"`
+}
+
+// ChannelsFor returns the channels the event should go out on
+func (p *NotificationPreference) ChannelsFor(event string) []NotificationChannel {
+	channels, ok := p.Events[event]
+	if !ok {
+		return DefaultChannels
+	}
+	return channels
+}
+
+// HasQuietHours reports whether quiet hours are configured
+func (p *NotificationPreference) HasQuietHours() bool {
+	return p.QuietHoursStart != "" && p.QuietHoursEnd != ""
+}
+
+// DefaultNotificationPreference is used for users who never saved any settings
+func DefaultNotificationPreference(userID uuid.UUID) *NotificationPreference {
+	return &NotificationPreference{UserID: userID, Events: EventChannels{}}
+}
@@ -0,0 +1,47 @@
+package domain
+
+// Permission is one thing a user may do. Routes and services check permissions,
+// never role names, so a new role is just a new entry in rolePermissions.
+type Permission string
+
+const (
+	PermBookAppointments    Permission = "appointments:book"       // Book for yourself
+	PermBookForCustomers    Permission = "appointments:book_for"   // Book on behalf of any customer
+	PermServeAppointments   Permission = "appointments:serve"      // Confirm, decline, no-show, complete on your own calendar
+	PermReadAllAppointments Permission = "appointments:read_all"   // See every appointment and its history
+	PermManageAppointments  Permission = "appointments:manage_all" // Cancel, reschedule and transition any appointment
+	PermManageSchedule      Permission = "schedule:manage"         // Your availability and cancellation policy
+	PermViewReports         Permission = "reports:view"            // Dashboard, including revenue
+	PermViewAudit           Permission = "audit:view"              // Audit trail search
+	PermManageNotifications Permission = "notifications:manage"    // Outbox inspection and replay
+	PermManageServices      Permission = "services:manage"         // Service catalog
+	PermManageUsers         Permission = "users:manage"            // Roles, suspension, provider applications
+)
+
+// rolePermissions is the single source of truth for what each role may do
+var rolePermissions = map[UserRole][]Permission{
+	RoleCustomer: {
+		PermBookAppointments,
+	},
+	RoleProvider: {
+		PermServeAppointments,
+		PermManageSchedule,
+	},
+	RoleStaff: {
+		PermBookAppointments,
+		PermBookForCustomers,
+		PermReadAllAppointments,
+		PermManageAppointments,
+	},
+	RoleAdmin: {
+		PermBookAppointments,
+		PermBookForCustomers,
+		PermReadAllAppointments,
+		PermManageAppointments,
+		PermViewReports,
+		PermViewAudit,
+		PermManageNotifications,
+		PermManageServices,
+		PermManageUsers,
+	},
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProviderApplicationStatus string
+
+const (
+	ApplicationPending  ProviderApplicationStatus = "PENDING"
+	ApplicationApproved ProviderApplicationStatus = "APPROVED"
+	ApplicationRejected ProviderApplicationStatus = "REJECTED"
+)
+
+// ProviderApplication is a customer's request to offer services, reviewed by an admin.
+// Approval is the only way a self-registered account becomes a provider.
+type ProviderApplication struct {
+	ID         uuid.UUID                 `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID     uuid.UUID                 `gorm:"type:uuid;not null;index" json:"user_id"`
+	Message    string                    `gorm:"type:text" json:"message"` // What the applicant offers
+	Status     ProviderApplicationStatus `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"`
+	ReviewerID *uuid.UUID                `gorm:"type:uuid" json:"reviewer_id,omitempty"`
+	ReviewNote string                    `gorm:"type:text" json:"review_note,omitempty"`
+	ReviewedAt *time.Time                `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time                 `json:"created_at"`
+	UpdatedAt  time.Time                 `json:"updated_at"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReminderStatus string
+
+const (
+	ReminderPending   ReminderStatus = "PENDING"
+	ReminderSent      ReminderStatus = "SENT"
+	ReminderCancelled ReminderStatus = "CANCELLED" // Appointment was cancelled or moved
+	ReminderMissed    ReminderStatus = "MISSED"    // Appointment started before the reminder could go out
+)
+
+// AppointmentReminder is one scheduled reminder, e.g. "24h before". Stored in the
+// database so reminders survive restarts.
+type AppointmentReminder struct {
+	ID            uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	AppointmentID uuid.UUID      `gorm:"type:uuid;not null;index"`
+	OffsetMinutes int            `gorm:"not null"`
+	StartTime     time.Time      `gorm:"not null"` // Appointment start it was scheduled for
+	SendAt        time.Time      `gorm:"not null;index"`
+	Status        ReminderStatus `gorm:"type:varchar(20);not null;default:'PENDING';index"`
+	SentAt        *time.Time
+	CreatedAt     time.Time
+}
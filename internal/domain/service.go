@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Service is a bookable offering (e.g. "Haircut") with a fixed duration.
+// Buffers block the provider's calendar before/after the appointment itself.
+type Service struct {
+	ID                  uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name                string    `gorm:"type:varchar(100);not null"`
+	Description         string    `gorm:"type:text"`
+	DurationMinutes     int       `gorm:"not null"`
+	BufferBeforeMinutes int       `gorm:"not null;default:0"`
+	BufferAfterMinutes  int       `gorm:"not null;default:0"`
+	PriceCents          int64     `gorm:"not null;default:0"`
+	Currency            string    `gorm:"type:varchar(3);not null;default:'USD'"`
+
+	// Providers offering this service
+	Providers []User `gorm:"many2many:provider_services;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (s *Service) Duration() time.Duration {
+	return time.Duration(s.DurationMinutes) * time.Minute
+}
+
+func (s *Service) BufferBefore() time.Duration {
+	return time.Duration(s.BufferBeforeMinutes) * time.Minute
+}
+
+func (s *Service) BufferAfter() time.Duration {
+	return time.Duration(s.BufferAfterMinutes) * time.Minute
+}
@@ -1,6 +1,10 @@
 package domain
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,17 +15,106 @@ type UserRole string
 
 const (
 	RoleAdmin    UserRole = "admin"
+	RoleStaff    UserRole = "staff" // Front desk: books and manages appointments for customers
 	RoleProvider UserRole = "provider"
 	RoleCustomer UserRole = "customer"
 )
 
+// IsValid reports whether r is a known role
+func (r UserRole) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// UserRoles is the set of roles a user holds, stored as a JSON array
+type UserRoles []UserRole
+
+func (r UserRoles) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(r)
+	return string(b), err
+}
+
+func (r *UserRoles) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case nil:
+		*r = UserRoles{}
+		return nil
+	default:
+		return errors.New("unsupported type for UserRoles")
+	}
+	return json.Unmarshal(b, r)
+}
+
+// RolesFromNames converts role names, e.g. from token claims
+func RolesFromNames(names []string) UserRoles {
+	roles := make(UserRoles, len(names))
+	for i, name := range names {
+		roles[i] = UserRole(name)
+	}
+	return roles
+}
+
+func (r UserRoles) Has(role UserRole) bool {
+	return slices.Contains(r, role)
+}
+
+// Can reports whether any of the roles grants the permission
+func (r UserRoles) Can(permission Permission) bool {
+	for _, role := range r {
+		if slices.Contains(rolePermissions[role], permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions lists everything the roles grant, without duplicates
+func (r UserRoles) Permissions() []Permission {
+	var permissions []Permission
+	for _, role := range r {
+		for _, p := range rolePermissions[role] {
+			if !slices.Contains(permissions, p) {
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	return permissions
+}
+
+// Primary is the most privileged role, used where one role has to stand for the user
+// (e.g. the actor role in the audit trail)
+func (r UserRoles) Primary() UserRole {
+	for _, role := range []UserRole{RoleAdmin, RoleStaff, RoleProvider, RoleCustomer} {
+		if r.Has(role) {
+			return role
+		}
+	}
+	return RoleCustomer
+}
+
 type User struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	Name      string    `gorm:"type:varchar(100);not null"`
-	Email     string    `gorm:"type:varchar(100);uniqueIndex;not null"`
-	Password  string    `gorm:"not null"`
-	Role      UserRole  `gorm:"type:varchar(20);default:'customer'"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ID              uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name            string         `gorm:"type:varchar(100);not null" json:"name"`
+	Email           string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
+	Password        string         `gorm:"not null" json:"-"`
+	Roles           UserRoles      `gorm:"type:jsonb;not null;default:'[\"customer\"]'" json:"roles"`
+	TimeZone        string         `gorm:"type:varchar(64);not null;default:'UTC'" json:"time_zone"` // IANA name, e.g. "Europe/Berlin"
+	Locale          string         `gorm:"type:varchar(10);not null;default:'en'" json:"locale"`     // Language of notifications
+	SuspendedAt     *time.Time     `json:"suspended_at,omitempty"`                                   // Set by an admin; blocks sign-in
+	EmailVerifiedAt *time.Time     `json:"email_verified_at,omitempty"`                              // Unverified accounts can sign in but not book
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting   WaitlistStatus = "WAITING"   // In the queue
+	WaitlistOffered   WaitlistStatus = "OFFERED"   // A freed slot is held for this customer
+	WaitlistClaimed   WaitlistStatus = "CLAIMED"   // Offer turned into an appointment
+	WaitlistExpired   WaitlistStatus = "EXPIRED"   // Offer ran out; the slot passed to the next customer
+	WaitlistCancelled WaitlistStatus = "CANCELLED" // Customer left the waitlist
+)
+
+// WaitlistEntry queues a customer for a provider/service on a date (provider's local date)
+type WaitlistEntry struct {
+	ID         uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	CustomerID uuid.UUID      `gorm:"type:uuid;not null;index" json:"customer_id"`
+	ProviderID uuid.UUID      `gorm:"type:uuid;not null;index:idx_waitlist_provider_date" json:"provider_id"`
+	ServiceID  uuid.UUID      `gorm:"type:uuid;not null" json:"service_id"`
+	Date       time.Time      `gorm:"type:date;not null;index:idx_waitlist_provider_date" json:"date"`
+	Status     WaitlistStatus `gorm:"type:varchar(20);not null;default:'WAITING';index" json:"status"`
+
+	// Set while an offer is outstanding
+	HoldID         *uuid.UUID `gorm:"type:uuid" json:"hold_id,omitempty"`
+	OfferedStart   *time.Time `json:"offered_start,omitempty"`
+	OfferExpiresAt *time.Time `gorm:"index" json:"offer_expires_at,omitempty"`
+
+	// The freed range the offer was carved from, so it can pass to the next customer
+	FreedStart *time.Time `json:"-"`
+	FreedEnd   *time.Time `json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,92 @@
+package event
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Event is anything published on the bus. Name identifies the event type.
+type Event interface {
+	Name() string
+}
+
+type txHandler func(tx *gorm.DB, e Event) error
+
+type handler func(e Event)
+
+// Bus dispatches domain events to subscribers in two phases:
+//
+//   - PublishTx runs OnTx subscribers inside the publisher's transaction. They write
+//     things that must commit or roll back with the change (audit, outbox, reminders),
+//     and an error aborts the change.
+//   - Publish runs On subscribers after commit for side effects outside the database
+//     (cache, WebSocket, webhooks). A failing subscriber never affects the others.
+type Bus struct {
+	mu         sync.RWMutex
+	txHandlers map[string][]txHandler
+	handlers   map[string][]handler
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		txHandlers: make(map[string][]txHandler),
+		handlers:   make(map[string][]handler),
+	}
+}
+
+// OnTx subscribes h to events of type T, run inside the publishing transaction
+func OnTx[T Event](b *Bus, h func(tx *gorm.DB, e T) error) {
+	var zero T
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.txHandlers[zero.Name()] = append(b.txHandlers[zero.Name()], func(tx *gorm.DB, e Event) error {
+		return h(tx, e.(T))
+	})
+}
+
+// On subscribes h to events of type T, run after the change has committed
+func On[T Event](b *Bus, h func(e T)) {
+	var zero T
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[zero.Name()] = append(b.handlers[zero.Name()], func(e Event) {
+		h(e.(T))
+	})
+}
+
+// PublishTx runs the transactional subscribers in subscription order and stops at the
+// first error, which the publisher should treat as a reason to roll back
+func (b *Bus) PublishTx(tx *gorm.DB, e Event) error {
+	b.mu.RLock()
+	subscribers := b.txHandlers[e.Name()]
+	b.mu.RUnlock()
+
+	for _, h := range subscribers {
+		if err := h(tx, e); err != nil {
+			return fmt.Errorf("%s subscriber: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Publish runs the after-commit subscribers. Each one is isolated: a panic is logged
+// and the remaining subscribers still run.
+func (b *Bus) Publish(e Event) {
+	b.mu.RLock()
+	subscribers := b.handlers[e.Name()]
+	b.mu.RUnlock()
+
+	for _, h := range subscribers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Event subscriber for %s panicked: %v", e.Name(), r)
+				}
+			}()
+			h(e)
+		}()
+	}
+}
@@ -0,0 +1,82 @@
+package event
+
+import (
+	"appointment-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AppointmentEvent is the part every appointment event shares
+type AppointmentEvent struct {
+	Appointment *domain.Appointment // State after the change
+	Actor       domain.Actor
+	OldStatus   domain.AppointmentStatus // Empty when the status didn't exist yet
+	Reason      string                   // For the audit trail
+}
+
+type AppointmentBooked struct {
+	AppointmentEvent
+}
+
+func (AppointmentBooked) Name() string { return "appointment.booked" }
+
+// AppointmentCancelled covers cancellations by either side and provider declines
+type AppointmentCancelled struct {
+	AppointmentEvent
+	Note string // Free-text message for the other party
+}
+
+func (AppointmentCancelled) Name() string { return "appointment.cancelled" }
+
+type AppointmentRescheduled struct {
+	AppointmentEvent
+	OldStartTime  time.Time
+	OldBlockStart time.Time
+	OldBlockEnd   time.Time
+}
+
+func (AppointmentRescheduled) Name() string { return "appointment.rescheduled" }
+
+// AppointmentStatusChanged covers provider transitions other than a decline:
+// confirm, no-show and complete
+type AppointmentStatusChanged struct {
+	AppointmentEvent
+}
+
+func (AppointmentStatusChanged) Name() string { return "appointment.status_changed" }
+
+// AvailabilityChanged is published when a provider's bookable time changes.
+// Date ("2006-01-02", provider-local) is empty when the weekly schedule changed.
+type AvailabilityChanged struct {
+	ProviderID uuid.UUID
+	Date       string
+}
+
+func (AvailabilityChanged) Name() string { return "availability.changed" }
+
+// WaitlistOfferMade is published when a freed slot is held for a waiting customer
+type WaitlistOfferMade struct {
+	EntryID    uuid.UUID
+	CustomerID uuid.UUID
+	ProviderID uuid.UUID
+	Slot       time.Time
+	ExpiresAt  time.Time
+}
+
+func (WaitlistOfferMade) Name() string { return "waitlist.offer_made" }
+
+// SessionsRevoked is published when login sessions end early: logout, refresh token
+// reuse, or an admin or password reset signing the user out everywhere
+type SessionsRevoked struct {
+	SessionIDs []uuid.UUID
+}
+
+func (SessionsRevoked) Name() string { return "sessions.revoked" }
+
+// UserRegistered is published after a new account is created
+type UserRegistered struct {
+	UserID uuid.UUID
+}
+
+func (UserRegistered) Name() string { return "user.registered" }
@@ -8,11 +8,12 @@ import (
 )
 
 type AdminHandler struct {
-	service *service.ReportService
+	service      *service.ReportService
+	auditService *service.AuditService
 }
 
-func NewAdminHandler(service *service.ReportService) *AdminHandler {
-	return &AdminHandler{service: service}
+func NewAdminHandler(service *service.ReportService, auditService *service.AuditService) *AdminHandler {
+	return &AdminHandler{service: service, auditService: auditService}
 }
 
 func (h *AdminHandler) GetDashboard(c *gin.Context) {
@@ -24,3 +25,20 @@ func (h *AdminHandler) GetDashboard(c *gin.Context) {
 
 	c.JSON(http.StatusOK, data)
 }
+
+// SearchAudit handles GET /admin/audit
+func (h *AdminHandler) SearchAudit(c *gin.Context) {
+	var query service.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.auditService.Search(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"appointment-booking/internal/domain"
 	"appointment-booking/internal/service"
+	"errors"
 	"net/http"
 	"time"
 
@@ -13,9 +15,15 @@ type AppointmentHandler struct {
 	service *service.AppointmentService
 }
 
+// The new end time is derived from the appointment's current duration
 type RescheduleInput struct {
 	StartTime time.Time `json:"start_time" binding:"required"`
-	EndTime   time.Time `json:"end_time" binding:"required"`
+	Reason    string    `json:"reason"`
+}
+
+// ReasonInput is the optional body of status-changing actions
+type ReasonInput struct {
+	Reason string `json:"reason"`
 }
 
 func NewAppointmentHandler(service *service.AppointmentService) *AppointmentHandler {
@@ -30,18 +38,18 @@ func (h *AppointmentHandler) Create(c *gin.Context) {
 	}
 
 	// Get User ID from JWT Context
-	userIDInterface, exists := c.Get("userID")
-	if !exists {
+	if _, exists := c.Get("userID"); !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
-	userID := userIDInterface.(uuid.UUID)
 
-	appointment, err := h.service.BookAppointment(userID, input)
+	appointment, err := h.service.BookAppointment(currentActor(c), input)
 	if err != nil {
 		// Differentiate errors (logic vs server)
-		if err.Error() == "time slot is not available" {
+		if errors.Is(err, service.ErrSlotTaken) || errors.Is(err, service.ErrSlotHeld) {
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		}
@@ -60,13 +68,23 @@ func (h *AppointmentHandler) Cancel(c *gin.Context) {
 		return
 	}
 
-	userID := c.MustGet("userID").(uuid.UUID)
-
-	if err := h.service.CancelAppointment(id, userID); err != nil {
+	var input service.CancelInput
+	if err := bindOptionalJSON(c, &input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	if err := h.service.CancelAppointment(id, currentActor(c), input); err != nil {
+		if errors.Is(err, service.ErrInvalidTransition) || errors.Is(err, service.ErrPolicyViolation) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Appointment cancelled"})
 }
 
@@ -85,12 +103,111 @@ func (h *AppointmentHandler) Reschedule(c *gin.Context) {
 		return
 	}
 
-	userID := c.MustGet("userID").(uuid.UUID)
-
-	if err := h.service.RescheduleAppointment(id, userID, input.StartTime, input.EndTime); err != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": err.Error()}) // 409 if slot taken
+	if err := h.service.RescheduleAppointment(id, currentActor(c), input.StartTime, input.Reason); err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()}) // 409 if slot taken
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Appointment rescheduled"})
 }
+
+// Confirm handles PUT /appointments/:id/confirm
+func (h *AppointmentHandler) Confirm(c *gin.Context) {
+	h.providerAction(c, h.service.ConfirmAppointment, "Appointment confirmed")
+}
+
+// Decline handles PUT /appointments/:id/decline
+func (h *AppointmentHandler) Decline(c *gin.Context) {
+	h.providerAction(c, h.service.DeclineAppointment, "Appointment declined")
+}
+
+// NoShow handles PUT /appointments/:id/no-show
+func (h *AppointmentHandler) NoShow(c *gin.Context) {
+	h.providerAction(c, h.service.MarkNoShow, "Appointment marked as no-show")
+}
+
+// Complete handles PUT /appointments/:id/complete
+func (h *AppointmentHandler) Complete(c *gin.Context) {
+	h.providerAction(c, h.service.CompleteAppointment, "Appointment completed")
+}
+
+// providerAction runs a provider status change and maps illegal transitions to 409
+func (h *AppointmentHandler) providerAction(c *gin.Context, action func(uuid.UUID, domain.Actor, string) error, message string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	var input ReasonInput
+	if err := bindOptionalJSON(c, &input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := action(id, currentActor(c), input.Reason); err != nil {
+		if errors.Is(err, service.ErrInvalidTransition) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// History handles GET /appointments/:id/history
+func (h *AppointmentHandler) History(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	history, err := h.service.GetHistory(id, currentActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+// List handles GET /appointments
+func (h *AppointmentHandler) List(c *gin.Context) {
+	var query service.AppointmentQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.service.ListAppointments(currentActor(c), query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Get handles GET /appointments/:id
+func (h *AppointmentHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	appointment, err := h.service.GetAppointment(id, currentActor(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, appointment)
+}
@@ -24,14 +24,86 @@ func (h *AvailabilityHandler) SetAvailability(c *gin.Context) {
 	}
 
 	providerID := c.MustGet("userID").(uuid.UUID)
-	// Ideally check if role == "provider"
 
-	if err := h.service.SetAvailability(providerID, input); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	avail, err := h.service.SetAvailability(providerID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Availability set", "availability": avail})
+}
+
+// DeleteAvailability handles DELETE /availability/:id
+func (h *AvailabilityHandler) DeleteAvailability(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	providerID := c.MustGet("userID").(uuid.UUID)
+
+	if err := h.service.DeleteAvailability(providerID, id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Availability removed"})
+}
+
+// AddOverride handles POST /availability/overrides
+func (h *AvailabilityHandler) AddOverride(c *gin.Context) {
+	var input service.AvailabilityOverrideInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	providerID := c.MustGet("userID").(uuid.UUID)
+
+	override, err := h.service.AddOverride(providerID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, override)
+}
+
+// DeleteOverride handles DELETE /availability/overrides/:id
+func (h *AvailabilityHandler) DeleteOverride(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Availability set"})
+	providerID := c.MustGet("userID").(uuid.UUID)
+
+	if err := h.service.DeleteOverride(providerID, id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Override removed"})
+}
+
+// GetSchedule handles GET /providers/:providerID/availability
+func (h *AvailabilityHandler) GetSchedule(c *gin.Context) {
+	providerID, err := uuid.Parse(c.Param("providerID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid provider ID"})
+		return
+	}
+
+	schedule, err := h.service.GetSchedule(providerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
 }
 
 func (h *AvailabilityHandler) GetSlots(c *gin.Context) {
@@ -44,11 +116,23 @@ func (h *AvailabilityHandler) GetSlots(c *gin.Context) {
 		return
 	}
 
-	slots, err := h.service.GetAvailableSlots(providerID, dateStr)
+	// Optional: ?service=<uuid> sizes slots for that service
+	var serviceID *uuid.UUID
+	if serviceStr := c.Query("service"); serviceStr != "" {
+		id, err := uuid.Parse(serviceStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service ID"})
+			return
+		}
+		serviceID = &id
+	}
+
+	// Optional: ?tz=America/New_York renders slots in the caller's zone
+	slots, err := h.service.GetAvailableSlots(providerID, dateStr, serviceID, c.Query("tz"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"slots": slots})
-}
\ No newline at end of file
+}
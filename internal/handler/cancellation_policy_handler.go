@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"appointment-booking/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CancellationPolicyHandler struct {
+	service *service.CancellationPolicyService
+}
+
+func NewCancellationPolicyHandler(service *service.CancellationPolicyService) *CancellationPolicyHandler {
+	return &CancellationPolicyHandler{service: service}
+}
+
+// Get handles GET /providers/:providerID/cancellation-policy
+func (h *CancellationPolicyHandler) Get(c *gin.Context) {
+	providerID, err := uuid.Parse(c.Param("providerID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid provider ID"})
+		return
+	}
+
+	policy, err := h.service.GetPolicy(providerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// Set handles PUT /cancellation-policy
+func (h *CancellationPolicyHandler) Set(c *gin.Context) {
+	var input service.CancellationPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := h.service.SetPolicy(currentActor(c), input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"appointment-booking/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CatalogHandler struct {
+	service *service.CatalogService
+}
+
+func NewCatalogHandler(service *service.CatalogService) *CatalogHandler {
+	return &CatalogHandler{service: service}
+}
+
+// List handles GET /services?provider=<uuid>
+func (h *CatalogHandler) List(c *gin.Context) {
+	var providerID *uuid.UUID
+	if providerStr := c.Query("provider"); providerStr != "" {
+		id, err := uuid.Parse(providerStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid provider ID"})
+			return
+		}
+		providerID = &id
+	}
+
+	services, err := h.service.ListServices(providerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load services"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"services": services})
+}
+
+// Get handles GET /services/:id
+func (h *CatalogHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	svc, err := h.service.GetService(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, svc)
+}
+
+// Create handles POST /admin/services
+func (h *CatalogHandler) Create(c *gin.Context) {
+	var input service.ServiceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc, err := h.service.CreateService(input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, svc)
+}
+
+// Update handles PUT /admin/services/:id
+func (h *CatalogHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	var input service.ServiceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc, err := h.service.UpdateService(id, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, svc)
+}
+
+// Delete handles DELETE /admin/services/:id
+func (h *CatalogHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	if err := h.service.DeleteService(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service deleted"})
+}
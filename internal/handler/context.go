@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"appointment-booking/internal/domain"
+	"errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// currentActor builds the acting user from the values AuthMiddleware stored
+func currentActor(c *gin.Context) domain.Actor {
+	return domain.Actor{
+		ID:    c.MustGet("userID").(uuid.UUID),
+		Roles: c.MustGet("roles").(domain.UserRoles),
+	}
+}
+
+// bindOptionalJSON binds the body if there is one; an empty body is not an error
+func bindOptionalJSON(c *gin.Context, obj interface{}) error {
+	if err := c.ShouldBindJSON(obj); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"appointment-booking/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HoldHandler struct {
+	service *service.HoldService
+}
+
+func NewHoldHandler(service *service.HoldService) *HoldHandler {
+	return &HoldHandler{service: service}
+}
+
+// Create handles POST /holds
+func (h *HoldHandler) Create(c *gin.Context) {
+	var input service.BookingInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, err := h.service.CreateHold(currentActor(c), input)
+	if err != nil {
+		if errors.Is(err, service.ErrSlotTaken) || errors.Is(err, service.ErrSlotHeld) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrHoldLimit) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// Confirm handles POST /holds/:id/confirm
+func (h *HoldHandler) Confirm(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	appointment, err := h.service.ConfirmHold(currentActor(c), id)
+	if err != nil {
+		if errors.Is(err, service.ErrSlotTaken) || errors.Is(err, service.ErrSlotHeld) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointment)
+}
+
+// Release handles DELETE /holds/:id
+func (h *HoldHandler) Release(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	if err := h.service.ReleaseHold(userID, id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Hold released"})
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"appointment-booking/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NotificationHandler struct {
+	service *service.NotificationService
+}
+
+func NewNotificationHandler(service *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// List handles GET /admin/notifications
+func (h *NotificationHandler) List(c *gin.Context) {
+	var query service.NotificationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	notifications, err := h.service.List(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications})
+}
+
+// Replay handles POST /admin/notifications/:id/replay
+func (h *NotificationHandler) Replay(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	notification, err := h.service.Replay(id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotReplayable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
+
+// GetPreferences handles GET /api/me/notifications
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	pref, err := h.service.GetPreferences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
+
+// UpdatePreferences handles PUT /api/me/notifications
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var input service.NotificationPreferencesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	pref, err := h.service.UpdatePreferences(userID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
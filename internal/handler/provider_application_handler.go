@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ProviderApplicationHandler struct {
+	service *service.ProviderApplicationService
+}
+
+func NewProviderApplicationHandler(service *service.ProviderApplicationService) *ProviderApplicationHandler {
+	return &ProviderApplicationHandler{service: service}
+}
+
+// Apply handles POST /api/provider-application
+func (h *ProviderApplicationHandler) Apply(c *gin.Context) {
+	var input service.ApplyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	application, err := h.service.Apply(userID, input)
+	if err != nil {
+		if errors.Is(err, service.ErrApplicationPending) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, application)
+}
+
+// Mine handles GET /api/provider-application
+func (h *ProviderApplicationHandler) Mine(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	application, err := h.service.Mine(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+// List handles GET /admin/provider-applications
+func (h *ProviderApplicationHandler) List(c *gin.Context) {
+	var query service.ApplicationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applications, err := h.service.List(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"applications": applications})
+}
+
+// Approve handles POST /admin/provider-applications/:id/approve
+func (h *ProviderApplicationHandler) Approve(c *gin.Context) {
+	h.review(c, h.service.Approve)
+}
+
+// Reject handles POST /admin/provider-applications/:id/reject
+func (h *ProviderApplicationHandler) Reject(c *gin.Context) {
+	h.review(c, h.service.Reject)
+}
+
+func (h *ProviderApplicationHandler) review(c *gin.Context, decide func(reviewerID, applicationID uuid.UUID, input service.ReviewInput) (*domain.ProviderApplication, error)) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	var input service.ReviewInput
+	if err := bindOptionalJSON(c, &input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reviewerID := c.MustGet("userID").(uuid.UUID)
+
+	application, err := decide(reviewerID, id, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrApplicationNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrApplicationReviewed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"appointment-booking/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type UserAdminHandler struct {
+	service *service.UserAdminService
+}
+
+func NewUserAdminHandler(service *service.UserAdminService) *UserAdminHandler {
+	return &UserAdminHandler{service: service}
+}
+
+// List handles GET /admin/users
+func (h *UserAdminHandler) List(c *gin.Context) {
+	var query service.UserQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	users, err := h.service.List(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// SetRoles handles PUT /admin/users/:id/roles
+func (h *UserAdminHandler) SetRoles(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	var input service.SetRolesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.service.SetRoles(c.MustGet("userID").(uuid.UUID), id, input)
+	if err != nil {
+		respondUserAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Suspend handles POST /admin/users/:id/suspend
+func (h *UserAdminHandler) Suspend(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	user, err := h.service.Suspend(c.MustGet("userID").(uuid.UUID), id)
+	if err != nil {
+		respondUserAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Reactivate handles POST /admin/users/:id/reactivate
+func (h *UserAdminHandler) Reactivate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	user, err := h.service.Reactivate(c.MustGet("userID").(uuid.UUID), id)
+	if err != nil {
+		respondUserAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Delete handles DELETE /admin/users/:id
+func (h *UserAdminHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	if err := h.service.Delete(c.MustGet("userID").(uuid.UUID), id); err != nil {
+		respondUserAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted"})
+}
+
+func respondUserAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrSelfModification):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
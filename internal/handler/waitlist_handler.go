@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"appointment-booking/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WaitlistHandler struct {
+	service *service.WaitlistService
+}
+
+func NewWaitlistHandler(service *service.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{service: service}
+}
+
+// Join handles POST /waitlist
+func (h *WaitlistHandler) Join(c *gin.Context) {
+	var input service.JoinWaitlistInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	entry, err := h.service.Join(userID, input)
+	if err != nil {
+		if errors.Is(err, service.ErrSlotsAvailable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// List handles GET /waitlist
+func (h *WaitlistHandler) List(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	entries, err := h.service.ListMine(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// Leave handles DELETE /waitlist/:id
+func (h *WaitlistHandler) Leave(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	if err := h.service.Leave(userID, id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left the waitlist"})
+}
+
+// Claim handles POST /waitlist/:id/claim
+func (h *WaitlistHandler) Claim(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		return
+	}
+
+	appointment, err := h.service.Claim(currentActor(c), id)
+	if err != nil {
+		if errors.Is(err, service.ErrSlotTaken) || errors.Is(err, service.ErrSlotHeld) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointment)
+}
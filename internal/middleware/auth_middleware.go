@@ -1,14 +1,18 @@
 package middleware
 
 import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/repository"
 	"appointment-booking/pkg/utils"
+	"log"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware accepts a valid access token whose session hasn't been revoked
+func AuthMiddleware(revocations *repository.RevocationRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -32,9 +36,25 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Fail closed: without the revocation list a logged-out token would pass
+		revoked, err := revocations.IsRevoked(c.Request.Context(), claims.SessionID)
+		if err != nil {
+			log.Printf("Revocation check failed: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify session"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			c.Abort()
+			return
+		}
+
 		// Store user info in context for next handlers
 		c.Set("userID", claims.UserID)
-		c.Set("role", claims.Role)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("roles", domain.RolesFromNames(claims.Roles))
+		c.Set("emailVerified", claims.EmailVerified)
 
 		c.Next()
 	}
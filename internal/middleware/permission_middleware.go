@@ -1,21 +1,24 @@
 package middleware
 
 import (
+	"appointment-booking/internal/domain"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RequireRole(requiredRole string) gin.HandlerFunc {
+// RequirePermission lets the request through if any of the user's roles grants the
+// permission. It must run after AuthMiddleware.
+func RequirePermission(permission domain.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
+		roles, exists := c.Get("roles")
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			c.Abort()
 			return
 		}
 
-		if role.(string) != requiredRole {
+		if !roles.(domain.UserRoles).Can(permission) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: Insufficient permissions"})
 			c.Abort()
 			return
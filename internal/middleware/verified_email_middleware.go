@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail lets the request through only for accounts that verified their
+// email address. It must run after AuthMiddleware.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool("emailVerified") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Verify your email address before booking"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"appointment-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AccountTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountTokenRepository(db *gorm.DB) *AccountTokenRepository {
+	return &AccountTokenRepository{db: db}
+}
+
+func (r *AccountTokenRepository) BeginTx() *gorm.DB {
+	return r.db.Begin()
+}
+
+func (r *AccountTokenRepository) Create(tx *gorm.DB, token *domain.AccountToken) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(token).Error
+}
+
+// FindForUpdate locks the token row, so a link redeemed twice at once only works once
+func (r *AccountTokenRepository) FindForUpdate(tx *gorm.DB, id uuid.UUID) (*domain.AccountToken, error) {
+	var token domain.AccountToken
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&token, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ConsumeAll marks every unused token of the user for purpose as used, invalidating
+// links that were already sent
+func (r *AccountTokenRepository) ConsumeAll(tx *gorm.DB, userID uuid.UUID, purpose domain.AccountTokenPurpose, at time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&domain.AccountToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", at).Error
+}
@@ -2,12 +2,23 @@ package repository
 
 import (
 	"appointment-booking/internal/domain"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrSlotTaken is returned when a write would make two active appointments of the
+// same provider overlap. It is raised by the appointments_no_overlap exclusion
+// constraint, so it holds even when application-level checks race.
+var ErrSlotTaken = errors.New("time slot is not available")
+
+// Postgres SQLSTATE for exclusion_violation
+const pgExclusionViolation = "23P01"
+
 type AppointmentRepository struct {
 	db *gorm.DB
 }
@@ -18,12 +29,39 @@ type AppointmentStats struct {
 	Count  int64
 }
 
+type CancellationBreakdown struct {
+	CancelledBy string
+	Reason      string
+	Late        bool
+	Count       int64
+}
+
 type ProviderLeaderboard struct {
 	ProviderID string
 	Name       string
 	Total      int64
 }
 
+// AppointmentFilter narrows List; nil/empty fields are ignored
+type AppointmentFilter struct {
+	CustomerID    *uuid.UUID
+	ProviderID    *uuid.UUID
+	ParticipantID *uuid.UUID // Customer or provider
+	ServiceID     *uuid.UUID
+	Statuses      []domain.AppointmentStatus
+	From          *time.Time // start_time >= From
+	To            *time.Time // start_time < To
+
+	SortField string // "start_time" or "created_at"
+	Desc      bool
+
+	// Keyset cursor: only rows strictly after (AfterValue, AfterID) in sort order
+	AfterValue *time.Time
+	AfterID    uuid.UUID
+
+	Limit int
+}
+
 func NewAppointmentRepository(db *gorm.DB) *AppointmentRepository {
 	return &AppointmentRepository{db: db}
 }
@@ -32,16 +70,24 @@ func (r *AppointmentRepository) Create(tx *gorm.DB, appointment *domain.Appointm
 	if tx == nil {
 		tx = r.db
 	}
-	return tx.Create(appointment).Error
+	return translateOverlapError(tx.Create(appointment).Error)
 }
 
-func (r *AppointmentRepository) HasOverlap(providerID uuid.UUID, start, end time.Time) (bool, error) {
+// HasOverlap checks whether [start, end) collides with the blocked time (buffers included)
+// of any active appointment. excludeID skips the appointment being rescheduled.
+func (r *AppointmentRepository) HasOverlap(tx *gorm.DB, providerID uuid.UUID, start, end time.Time, excludeID *uuid.UUID) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
 	var count int64
-	err := r.db.Model(&domain.Appointment{}).
+	query := tx.Model(&domain.Appointment{}).
 		Where("provider_id = ?", providerID).
 		Where("status IN ?", []domain.AppointmentStatus{domain.StatusPending, domain.StatusConfirmed}).
-		Where("start_time < ? AND end_time > ?", end, start).
-		Count(&count).Error
+		Where("block_start < ? AND block_end > ?", end, start)
+	if excludeID != nil {
+		query = query.Where("id <> ?", *excludeID)
+	}
+	err := query.Count(&count).Error
 
 	return count > 0, err
 }
@@ -50,6 +96,15 @@ func (r *AppointmentRepository) BeginTx() *gorm.DB {
 	return r.db.Begin()
 }
 
+// LockProvider takes a row lock on the provider inside tx, serializing concurrent
+// bookings for the same provider until the transaction ends
+func (r *AppointmentRepository) LockProvider(tx *gorm.DB, providerID uuid.UUID) error {
+	var provider domain.User
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&provider, "id = ? AND "+hasRole, providerID, domain.RoleProvider).Error
+}
+
 // Fetches an appointment (needed to check ownership/status before modifying)
 func (r *AppointmentRepository) FindByID(id uuid.UUID) (*domain.Appointment, error) {
 	var appt domain.Appointment
@@ -57,9 +112,73 @@ func (r *AppointmentRepository) FindByID(id uuid.UUID) (*domain.Appointment, err
 	return &appt, err
 }
 
+// List returns appointments matching the filter using keyset pagination on (sort field, id)
+func (r *AppointmentRepository) List(filter AppointmentFilter) ([]domain.Appointment, error) {
+	query := r.db.Model(&domain.Appointment{})
+
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.ParticipantID != nil {
+		query = query.Where("(customer_id = ? OR provider_id = ?)", *filter.ParticipantID, *filter.ParticipantID)
+	}
+	if filter.ProviderID != nil {
+		query = query.Where("provider_id = ?", *filter.ProviderID)
+	}
+	if filter.ServiceID != nil {
+		query = query.Where("service_id = ?", *filter.ServiceID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.From != nil {
+		query = query.Where("start_time >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("start_time < ?", *filter.To)
+	}
+
+	// SortField is whitelisted by the caller, never taken verbatim from a request
+	column := filter.SortField
+	direction, comparator := "asc", ">"
+	if filter.Desc {
+		direction, comparator = "desc", "<"
+	}
+
+	if filter.AfterValue != nil {
+		query = query.Where("("+column+", id) "+comparator+" (?, ?)", *filter.AfterValue, filter.AfterID)
+	}
+
+	var appointments []domain.Appointment
+	err := query.Order(column + " " + direction).
+		Order("id " + direction).
+		Limit(filter.Limit).
+		Find(&appointments).Error
+	return appointments, err
+}
+
+// FindByIDForUpdate fetches and row-locks an appointment inside tx
+func (r *AppointmentRepository) FindByIDForUpdate(tx *gorm.DB, id uuid.UUID) (*domain.Appointment, error) {
+	var appt domain.Appointment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&appt, "id = ?", id).Error
+	return &appt, err
+}
+
 // Saves changes to an existing appointment
-func (r *AppointmentRepository) Update(appt *domain.Appointment) error {
-	return r.db.Save(appt).Error
+func (r *AppointmentRepository) Update(tx *gorm.DB, appt *domain.Appointment) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return translateOverlapError(tx.Save(appt).Error)
+}
+
+// translateOverlapError maps the exclusion constraint violation to ErrSlotTaken
+func translateOverlapError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgExclusionViolation {
+		return ErrSlotTaken
+	}
+	return err
 }
 
 // GetStatsByDateRange returns count of appointments grouped by status
@@ -92,3 +211,17 @@ func (r *AppointmentRepository) GetTopProviders(limit int) ([]ProviderLeaderboar
 
 	return results, err
 }
+
+// GetCancellationStats groups cancellations made in the range by who cancelled, reason and lateness
+func (r *AppointmentRepository) GetCancellationStats(start, end time.Time) ([]CancellationBreakdown, error) {
+	var results []CancellationBreakdown
+
+	err := r.db.Model(&domain.Appointment{}).
+		Select("cancelled_by, cancellation_reason as reason, late_cancellation as late, count(*) as count").
+		Where("status = ?", domain.StatusCancelled).
+		Where("cancelled_at BETWEEN ? AND ?", start, end).
+		Group("cancelled_by, cancellation_reason, late_cancellation").
+		Scan(&results).Error
+
+	return results, err
+}
@@ -20,20 +20,72 @@ func (r *AvailabilityRepository) Save(availability *domain.Availability) error {
 	return r.db.Create(availability).Error
 }
 
-func (r *AvailabilityRepository) GetByProviderAndDay(providerID uuid.UUID, day int) (*domain.Availability, error) {
+// GetByProviderAndDay returns every weekly window for the given day, earliest first
+func (r *AvailabilityRepository) GetByProviderAndDay(providerID uuid.UUID, day int) ([]domain.Availability, error) {
+	var windows []domain.Availability
+	err := r.db.Where("provider_id = ? AND day_of_week = ?", providerID, day).
+		Order("start_time asc").
+		Find(&windows).Error
+	return windows, err
+}
+
+// GetByProvider returns the provider's full weekly schedule
+func (r *AvailabilityRepository) GetByProvider(providerID uuid.UUID) ([]domain.Availability, error) {
+	var windows []domain.Availability
+	err := r.db.Where("provider_id = ?", providerID).
+		Order("day_of_week asc, start_time asc").
+		Find(&windows).Error
+	return windows, err
+}
+
+func (r *AvailabilityRepository) FindByID(id uuid.UUID) (*domain.Availability, error) {
 	var availability domain.Availability
-	err := r.db.Where("provider_id = ? AND day_of_week = ?", providerID, day).First(&availability).Error
+	err := r.db.First(&availability, "id = ?", id).Error
 	return &availability, err
 }
 
-// Return all confirmed/pending appointments for a specific date
-func (r *AppointmentRepository) GetProviderAppointments(providerID uuid.UUID, date time.Time) ([]domain.Appointment, error) {
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
-	endOfDay := startOfDay.Add(24 * time.Hour)
+func (r *AvailabilityRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&domain.Availability{}, "id = ?", id).Error
+}
+
+func (r *AvailabilityRepository) SaveOverride(override *domain.AvailabilityOverride) error {
+	return r.db.Create(override).Error
+}
+
+// GetOverridesByProviderAndDate returns all exceptions for a single calendar date
+func (r *AvailabilityRepository) GetOverridesByProviderAndDate(providerID uuid.UUID, date time.Time) ([]domain.AvailabilityOverride, error) {
+	var overrides []domain.AvailabilityOverride
+	err := r.db.Where("provider_id = ? AND date = ?", providerID, date.Format("2006-01-02")).
+		Order("start_time asc").
+		Find(&overrides).Error
+	return overrides, err
+}
+
+// GetOverridesByProvider returns exceptions dated on or after `from`
+func (r *AvailabilityRepository) GetOverridesByProvider(providerID uuid.UUID, from time.Time) ([]domain.AvailabilityOverride, error) {
+	var overrides []domain.AvailabilityOverride
+	err := r.db.Where("provider_id = ? AND date >= ?", providerID, from.Format("2006-01-02")).
+		Order("date asc, start_time asc").
+		Find(&overrides).Error
+	return overrides, err
+}
+
+func (r *AvailabilityRepository) FindOverrideByID(id uuid.UUID) (*domain.AvailabilityOverride, error) {
+	var override domain.AvailabilityOverride
+	err := r.db.First(&override, "id = ?", id).Error
+	return &override, err
+}
+
+func (r *AvailabilityRepository) DeleteOverride(id uuid.UUID) error {
+	return r.db.Delete(&domain.AvailabilityOverride{}, "id = ?", id).Error
+}
 
+// Return all confirmed/pending appointments whose blocked time touches [startOfDay, endOfDay).
+// The bounds come from the caller so the day can be in the provider's time zone.
+func (r *AppointmentRepository) GetProviderAppointments(providerID uuid.UUID, startOfDay, endOfDay time.Time) ([]domain.Appointment, error) {
 	var appointments []domain.Appointment
 	err := r.db.Where("provider_id = ?", providerID).
-		Where("start_time >= ? AND start_time < ?", startOfDay, endOfDay).
+		Where("block_start < ? AND block_end > ?", endOfDay, startOfDay).
 		Where("status IN ?", []domain.AppointmentStatus{domain.StatusPending, domain.StatusConfirmed}).
 		Find(&appointments).Error
 
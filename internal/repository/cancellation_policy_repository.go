@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"appointment-booking/internal/domain"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CancellationPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewCancellationPolicyRepository(db *gorm.DB) *CancellationPolicyRepository {
+	return &CancellationPolicyRepository{db: db}
+}
+
+// FindByProvider returns the provider's policy, or the default one if none is stored
+func (r *CancellationPolicyRepository) FindByProvider(tx *gorm.DB, providerID uuid.UUID) (*domain.CancellationPolicy, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var policy domain.CancellationPolicy
+	err := tx.First(&policy, "provider_id = ?", providerID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.DefaultCancellationPolicy(providerID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Upsert creates or replaces the provider's policy
+func (r *CancellationPolicyRepository) Upsert(policy *domain.CancellationPolicy) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "provider_id"}},
+		UpdateAll: true,
+	}).Create(policy).Error
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"appointment-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// HistoryRepository is append-only: there is deliberately no Update or Delete
+type HistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewHistoryRepository(db *gorm.DB) *HistoryRepository {
+	return &HistoryRepository{db: db}
+}
+
+// AuditFilter narrows an audit search; zero values are ignored
+type AuditFilter struct {
+	AppointmentID *uuid.UUID
+	ActorID       *uuid.UUID
+	Action        domain.HistoryAction
+	From          *time.Time
+	To            *time.Time
+	Limit         int
+	Offset        int
+}
+
+// Create writes an entry, inside tx when given so it commits with the change it describes
+func (r *HistoryRepository) Create(tx *gorm.DB, entry *domain.AppointmentHistory) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(entry).Error
+}
+
+// ListByAppointment returns an appointment's history, oldest first
+func (r *HistoryRepository) ListByAppointment(appointmentID uuid.UUID) ([]domain.AppointmentHistory, error) {
+	var entries []domain.AppointmentHistory
+	err := r.db.Where("appointment_id = ?", appointmentID).
+		Order("created_at asc").
+		Find(&entries).Error
+	return entries, err
+}
+
+// Search returns matching entries, newest first
+func (r *HistoryRepository) Search(filter AuditFilter) ([]domain.AppointmentHistory, error) {
+	query := r.db.Model(&domain.AppointmentHistory{})
+
+	if filter.AppointmentID != nil {
+		query = query.Where("appointment_id = ?", *filter.AppointmentID)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var entries []domain.AppointmentHistory
+	err := query.Order("created_at desc").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"appointment-booking/internal/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrHoldConflict is returned when a new hold overlaps another live hold
+	ErrHoldConflict = errors.New("time slot is currently held by another customer")
+	// ErrHoldLimit is returned when the customer already has the maximum number of live holds
+	ErrHoldLimit = errors.New("too many active holds, complete or release one first")
+)
+
+// HoldRepository stores slot holds in Redis:
+//   - "hold:<id>" holds the full JSON with a TTL, for lookup by ID
+//   - "holds:<provider>" is a sorted set of holdIndexEntry scored by expiry (unix ms),
+//     used for overlap checks; expired members are trimmed lazily
+//   - "customer_holds:<customer>" is a sorted set of hold IDs scored by expiry, used to
+//     cap how many slots one customer can hold at once
+type HoldRepository struct {
+	redis *redis.Client
+}
+
+func NewHoldRepository(redis *redis.Client) *HoldRepository {
+	return &HoldRepository{redis: redis}
+}
+
+type holdIndexEntry struct {
+	ID         string `json:"id"`
+	CustomerID string `json:"customer_id"`
+	BlockStart int64  `json:"block_start"` // unix ms
+	BlockEnd   int64  `json:"block_end"`   // unix ms
+}
+
+// Atomically trims expired holds, enforces the customer's hold limit (0 = none),
+// rejects overlaps and registers the new hold. Running as one script means two
+// customers can never hold overlapping ranges and the limit can't be raced past.
+// Returns 1 on success, 0 on overlap and -1 when the limit is reached.
+var createHoldScript = redis.NewScript(`
+local index = KEYS[1]
+local holdKey = KEYS[2]
+local customerIndex = KEYS[3]
+local now = tonumber(ARGV[1])
+local blockStart = tonumber(ARGV[2])
+local blockEnd = tonumber(ARGV[3])
+local expiresAt = tonumber(ARGV[4])
+local limit = tonumber(ARGV[8])
+
+redis.call('ZREMRANGEBYSCORE', index, '-inf', now)
+redis.call('ZREMRANGEBYSCORE', customerIndex, '-inf', now)
+
+if limit > 0 and redis.call('ZCARD', customerIndex) >= limit then
+	return -1
+end
+
+for _, member in ipairs(redis.call('ZRANGE', index, 0, -1)) do
+	local h = cjson.decode(member)
+	if h.block_start < blockEnd and h.block_end > blockStart then
+		return 0
+	end
+end
+
+redis.call('ZADD', index, expiresAt, ARGV[5])
+redis.call('SET', holdKey, ARGV[6], 'PXAT', expiresAt)
+redis.call('ZADD', customerIndex, expiresAt, ARGV[7])
+
+local latest = redis.call('ZRANGE', index, -1, -1, 'WITHSCORES')
+redis.call('PEXPIREAT', index, latest[2])
+latest = redis.call('ZRANGE', customerIndex, -1, -1, 'WITHSCORES')
+redis.call('PEXPIREAT', customerIndex, latest[2])
+return 1
+`)
+
+func holdKey(id uuid.UUID) string {
+	return fmt.Sprintf("hold:%s", id.String())
+}
+
+func holdIndexKey(providerID uuid.UUID) string {
+	return fmt.Sprintf("holds:%s", providerID.String())
+}
+
+func customerHoldsKey(customerID uuid.UUID) string {
+	return fmt.Sprintf("customer_holds:%s", customerID.String())
+}
+
+// Create stores the hold unless it overlaps a live hold for the same provider or the
+// customer already has limit live holds. A limit of 0 means no limit.
+func (r *HoldRepository) Create(ctx context.Context, hold *domain.SlotHold, limit int) error {
+	member, err := json.Marshal(indexEntryFor(hold))
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(hold)
+	if err != nil {
+		return err
+	}
+
+	res, err := createHoldScript.Run(ctx, r.redis,
+		[]string{holdIndexKey(hold.ProviderID), holdKey(hold.ID), customerHoldsKey(hold.CustomerID)},
+		time.Now().UnixMilli(),
+		hold.BlockStart.UnixMilli(),
+		hold.BlockEnd.UnixMilli(),
+		hold.ExpiresAt.UnixMilli(),
+		string(member),
+		string(payload),
+		hold.ID.String(),
+		limit,
+	).Int()
+	if err != nil {
+		return err
+	}
+	switch res {
+	case 0:
+		return ErrHoldConflict
+	case -1:
+		return ErrHoldLimit
+	}
+	return nil
+}
+
+// FindByID returns redis.Nil once the hold has expired or been removed
+func (r *HoldRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.SlotHold, error) {
+	val, err := r.redis.Get(ctx, holdKey(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var hold domain.SlotHold
+	if err := json.Unmarshal([]byte(val), &hold); err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// ListActive returns the live holds of a provider. Only ID, CustomerID, ProviderID,
+// BlockStart, BlockEnd and ExpiresAt are populated.
+func (r *HoldRepository) ListActive(ctx context.Context, providerID uuid.UUID) ([]domain.SlotHold, error) {
+	now := time.Now().UnixMilli()
+	members, err := r.redis.ZRangeByScoreWithScores(ctx, holdIndexKey(providerID), &redis.ZRangeBy{
+		Min: fmt.Sprintf("(%d", now),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	holds := make([]domain.SlotHold, 0, len(members))
+	for _, m := range members {
+		var entry holdIndexEntry
+		if err := json.Unmarshal([]byte(m.Member.(string)), &entry); err != nil {
+			continue
+		}
+		id, _ := uuid.Parse(entry.ID)
+		customerID, _ := uuid.Parse(entry.CustomerID)
+		holds = append(holds, domain.SlotHold{
+			ID:         id,
+			CustomerID: customerID,
+			ProviderID: providerID,
+			BlockStart: time.UnixMilli(entry.BlockStart),
+			BlockEnd:   time.UnixMilli(entry.BlockEnd),
+			ExpiresAt:  time.UnixMilli(int64(m.Score)),
+		})
+	}
+	return holds, nil
+}
+
+func (r *HoldRepository) Delete(ctx context.Context, hold *domain.SlotHold) error {
+	member, err := json.Marshal(indexEntryFor(hold))
+	if err != nil {
+		return err
+	}
+
+	pipe := r.redis.TxPipeline()
+	pipe.ZRem(ctx, holdIndexKey(hold.ProviderID), string(member))
+	pipe.Del(ctx, holdKey(hold.ID))
+	pipe.ZRem(ctx, customerHoldsKey(hold.CustomerID), hold.ID.String())
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func indexEntryFor(hold *domain.SlotHold) holdIndexEntry {
+	return holdIndexEntry{
+		ID:         hold.ID.String(),
+		CustomerID: hold.CustomerID.String(),
+		BlockStart: hold.BlockStart.UnixMilli(),
+		BlockEnd:   hold.BlockEnd.UnixMilli(),
+	}
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"appointment-booking/internal/domain"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepository(db *gorm.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{db: db}
+}
+
+// FindByUser returns the user's preferences, or the defaults if none are stored
+func (r *NotificationPreferenceRepository) FindByUser(tx *gorm.DB, userID uuid.UUID) (*domain.NotificationPreference, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var pref domain.NotificationPreference
+	err := tx.First(&pref, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.DefaultNotificationPreference(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+// Upsert creates or replaces the user's preferences
+func (r *NotificationPreferenceRepository) Upsert(pref *domain.NotificationPreference) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"events", "webhook_url", "quiet_hours_start", "quiet_hours_end"}),
+	}).Create(pref).Error
+}
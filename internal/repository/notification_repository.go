@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"appointment-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// NotificationFilter narrows an outbox listing; zero values are ignored
+type NotificationFilter struct {
+	Status domain.NotificationStatus
+	UserID *uuid.UUID
+	Limit  int
+	Offset int
+}
+
+// Create writes to the outbox, inside tx when given so it commits with the change it announces
+func (r *NotificationRepository) Create(tx *gorm.DB, n *domain.Notification) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(n).Error
+}
+
+func (r *NotificationRepository) FindByID(id uuid.UUID) (*domain.Notification, error) {
+	var n domain.Notification
+	err := r.db.First(&n, "id = ?", id).Error
+	return &n, err
+}
+
+// ClaimDue leases up to limit due notifications and counts the attempt. The lease pushes
+// next_attempt_at forward, so other instances skip them; if this instance dies mid-send
+// they become due again once the lease runs out.
+func (r *NotificationRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	err := r.db.Raw(`
+		UPDATE notifications SET next_attempt_at = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), now, domain.NotificationPending, now, limit,
+	).Scan(&notifications).Error
+	return notifications, err
+}
+
+func (r *NotificationRepository) MarkSent(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&domain.Notification{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": domain.NotificationSent, "sent_at": at, "last_error": ""}).Error
+}
+
+// MarkFailed schedules another attempt at next
+func (r *NotificationRepository) MarkFailed(id uuid.UUID, next time.Time, lastErr string) error {
+	return r.db.Model(&domain.Notification{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"next_attempt_at": next, "last_error": lastErr}).Error
+}
+
+func (r *NotificationRepository) MarkDead(id uuid.UUID, lastErr string) error {
+	return r.db.Model(&domain.Notification{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": domain.NotificationDead, "last_error": lastErr}).Error
+}
+
+// Defer postpones delivery to until without spending the attempt that claimed it
+func (r *NotificationRepository) Defer(id uuid.UUID, until time.Time) error {
+	return r.db.Model(&domain.Notification{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"next_attempt_at": until, "attempts": gorm.Expr("GREATEST(attempts - 1, 0)")}).Error
+}
+
+func (r *NotificationRepository) MarkSkipped(id uuid.UUID) error {
+	return r.db.Model(&domain.Notification{}).Where("id = ?", id).
+		Update("status", domain.NotificationSkipped).Error
+}
+
+// Replay puts a dead notification back in the queue with a fresh attempt budget.
+// It returns false if the notification is not dead.
+func (r *NotificationRepository) Replay(id uuid.UUID, now time.Time) (bool, error) {
+	res := r.db.Model(&domain.Notification{}).
+		Where("id = ? AND status = ?", id, domain.NotificationDead).
+		Updates(map[string]interface{}{"status": domain.NotificationPending, "attempts": 0, "next_attempt_at": now})
+	return res.RowsAffected == 1, res.Error
+}
+
+// List returns matching notifications, newest first
+func (r *NotificationRepository) List(filter NotificationFilter) ([]domain.Notification, error) {
+	query := r.db.Model(&domain.Notification{})
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+
+	var notifications []domain.Notification
+	err := query.Order("created_at desc").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&notifications).Error
+	return notifications, err
+}
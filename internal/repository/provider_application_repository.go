@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"appointment-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProviderApplicationRepository struct {
+	db *gorm.DB
+}
+
+func NewProviderApplicationRepository(db *gorm.DB) *ProviderApplicationRepository {
+	return &ProviderApplicationRepository{db: db}
+}
+
+func (r *ProviderApplicationRepository) BeginTx() *gorm.DB {
+	return r.db.Begin()
+}
+
+func (r *ProviderApplicationRepository) Create(application *domain.ProviderApplication) error {
+	return r.db.Create(application).Error
+}
+
+func (r *ProviderApplicationRepository) FindByID(id uuid.UUID) (*domain.ProviderApplication, error) {
+	var application domain.ProviderApplication
+	err := r.db.First(&application, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+// FindLatestByUser returns the user's most recent application
+func (r *ProviderApplicationRepository) FindLatestByUser(userID uuid.UUID) (*domain.ProviderApplication, error) {
+	var application domain.ProviderApplication
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&application).Error
+	if err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+func (r *ProviderApplicationRepository) HasPending(userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.ProviderApplication{}).
+		Where("user_id = ? AND status = ?", userID, domain.ApplicationPending).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// List returns applications oldest first, so the review queue is worked in order
+func (r *ProviderApplicationRepository) List(status domain.ProviderApplicationStatus, limit, offset int) ([]domain.ProviderApplication, error) {
+	query := r.db.Model(&domain.ProviderApplication{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var applications []domain.ProviderApplication
+	err := query.Order("created_at ASC").Limit(limit).Offset(offset).Find(&applications).Error
+	return applications, err
+}
+
+// Review records the decision only if the application is still pending. It returns
+// false if another admin reviewed it first.
+func (r *ProviderApplicationRepository) Review(tx *gorm.DB, id uuid.UUID, status domain.ProviderApplicationStatus, reviewerID uuid.UUID, note string) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	res := tx.Model(&domain.ProviderApplication{}).
+		Where("id = ? AND status = ?", id, domain.ApplicationPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewer_id": reviewerID,
+			"review_note": note,
+			"reviewed_at": time.Now(),
+		})
+	return res.RowsAffected == 1, res.Error
+}
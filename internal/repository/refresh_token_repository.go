@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"appointment-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) BeginTx() *gorm.DB {
+	return r.db.Begin()
+}
+
+func (r *RefreshTokenRepository) Create(tx *gorm.DB, token *domain.RefreshToken) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(token).Error
+}
+
+// FindByHashForUpdate locks the token row, so two refreshes with the same token are
+// serialized and the second one sees it as used
+func (r *RefreshTokenRepository) FindByHashForUpdate(tx *gorm.DB, hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&token, "token_hash = ?", hash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *RefreshTokenRepository) FindByHash(hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.db.First(&token, "token_hash = ?", hash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *RefreshTokenRepository) MarkUsed(tx *gorm.DB, id uuid.UUID, at time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&domain.RefreshToken{}).Where("id = ?", id).Update("used_at", at).Error
+}
+
+// RevokeSession revokes every token of the session
+func (r *RefreshTokenRepository) RevokeSession(tx *gorm.DB, sessionID uuid.UUID) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&domain.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUser revokes every token of the user and returns the sessions that were still open
+func (r *RefreshTokenRepository) RevokeUser(tx *gorm.DB, userID uuid.UUID) ([]uuid.UUID, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var sessionIDs []uuid.UUID
+	err := tx.Raw(`
+		UPDATE refresh_tokens SET revoked_at = ?
+		WHERE user_id = ? AND revoked_at IS NULL
+		RETURNING session_id
+	`, time.Now(), userID).Scan(&sessionIDs).Error
+	return sessionIDs, err
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"appointment-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReminderRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) *ReminderRepository {
+	return &ReminderRepository{db: db}
+}
+
+// CreateBatch schedules reminders, inside tx when given so they commit with the booking
+func (r *ReminderRepository) CreateBatch(tx *gorm.DB, reminders []domain.AppointmentReminder) error {
+	if len(reminders) == 0 {
+		return nil
+	}
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(&reminders).Error
+}
+
+// CancelPending drops every reminder of the appointment that has not gone out yet
+func (r *ReminderRepository) CancelPending(tx *gorm.DB, appointmentID uuid.UUID) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&domain.AppointmentReminder{}).
+		Where("appointment_id = ? AND status = ?", appointmentID, domain.ReminderPending).
+		Update("status", domain.ReminderCancelled).Error
+}
+
+func (r *ReminderRepository) BeginTx() *gorm.DB {
+	return r.db.Begin()
+}
+
+// ClaimDue marks up to limit due reminders as sent and returns them. Rows are picked with
+// SKIP LOCKED, so concurrent instances each claim a disjoint set and nothing is sent twice.
+func (r *ReminderRepository) ClaimDue(tx *gorm.DB, now time.Time, limit int) ([]domain.AppointmentReminder, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var reminders []domain.AppointmentReminder
+	err := tx.Raw(`
+		UPDATE appointment_reminders SET status = ?, sent_at = ?
+		WHERE id IN (
+			SELECT id FROM appointment_reminders
+			WHERE status = ? AND send_at <= ? AND start_time > ?
+			ORDER BY send_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		domain.ReminderSent, now, domain.ReminderPending, now, now, limit,
+	).Scan(&reminders).Error
+	return reminders, err
+}
+
+// MarkMissed retires pending reminders whose appointment has already started
+func (r *ReminderRepository) MarkMissed(now time.Time) error {
+	return r.db.Model(&domain.AppointmentReminder{}).
+		Where("status = ? AND start_time <= ?", domain.ReminderPending, now).
+		Update("status", domain.ReminderMissed).Error
+}
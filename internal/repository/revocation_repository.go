@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RevocationRepository is the list of revoked login sessions, checked on every
+// authenticated request. An entry only has to outlive the session's access tokens,
+// so it is stored with the access token lifetime as TTL.
+type RevocationRepository struct {
+	redis *redis.Client
+}
+
+func NewRevocationRepository(redis *redis.Client) *RevocationRepository {
+	return &RevocationRepository{redis: redis}
+}
+
+func revokedSessionKey(sessionID uuid.UUID) string {
+	return fmt.Sprintf("revoked_session:%s", sessionID)
+}
+
+func (r *RevocationRepository) Revoke(ctx context.Context, ttl time.Duration, sessionIDs ...uuid.UUID) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	pipe := r.redis.Pipeline()
+	for _, id := range sessionIDs {
+		pipe.Set(ctx, revokedSessionKey(id), 1, ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RevocationRepository) IsRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	n, err := r.redis.Exists(ctx, revokedSessionKey(sessionID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"appointment-booking/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ServiceRepository struct {
+	db *gorm.DB
+}
+
+func NewServiceRepository(db *gorm.DB) *ServiceRepository {
+	return &ServiceRepository{db: db}
+}
+
+func (r *ServiceRepository) Create(svc *domain.Service) error {
+	return r.db.Create(svc).Error
+}
+
+func (r *ServiceRepository) FindByID(id uuid.UUID) (*domain.Service, error) {
+	var svc domain.Service
+	err := r.db.Preload("Providers").First(&svc, "id = ?", id).Error
+	return &svc, err
+}
+
+// List returns all services, optionally only those offered by one provider
+func (r *ServiceRepository) List(providerID *uuid.UUID) ([]domain.Service, error) {
+	var services []domain.Service
+	query := r.db.Preload("Providers").Order("name asc")
+	if providerID != nil {
+		query = query.
+			Joins("JOIN provider_services ON provider_services.service_id = services.id").
+			Where("provider_services.user_id = ?", *providerID)
+	}
+	err := query.Find(&services).Error
+	return services, err
+}
+
+func (r *ServiceRepository) Update(svc *domain.Service) error {
+	return r.db.Omit("Providers").Save(svc).Error
+}
+
+// ReplaceProviders sets exactly which providers offer the service
+func (r *ServiceRepository) ReplaceProviders(svc *domain.Service, providers []domain.User) error {
+	return r.db.Model(svc).Association("Providers").Replace(providers)
+}
+
+func (r *ServiceRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&domain.Service{}, "id = ?", id).Error
+}
+
+// IsOfferedBy reports whether the provider offers the service
+func (r *ServiceRepository) IsOfferedBy(serviceID, providerID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Table("provider_services").
+		Where("service_id = ? AND user_id = ?", serviceID, providerID).
+		Count(&count).Error
+	return count > 0, err
+}
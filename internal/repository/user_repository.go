@@ -2,10 +2,15 @@ package repository
 
 import (
 	"appointment-booking/internal/domain"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// hasRole matches users holding the role given as the argument
+const hasRole = "roles @> jsonb_build_array(?::text)"
+
 type UserRepository struct {
 	db *gorm.DB
 }
@@ -27,3 +32,95 @@ func (r *UserRepository) FindByEmail(email string) (*domain.User, error) {
 	}
 	return &user, nil
 }
+
+func (r *UserRepository) FindByID(id uuid.UUID) (*domain.User, error) {
+	var user domain.User
+
+	err := r.db.First(&user, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindProvidersByIDs returns the users among ids that have the provider role
+func (r *UserRepository) FindProvidersByIDs(ids []uuid.UUID) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.Where("id IN ? AND "+hasRole, ids, domain.RoleProvider).Find(&users).Error
+	return users, err
+}
+
+// UpdateSettings replaces only the user's own display settings. Writing the whole row
+// back would undo a concurrent role change or suspension by an admin.
+func (r *UserRepository) UpdateSettings(id uuid.UUID, timeZone, locale string) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"time_zone": timeZone,
+		"locale":    locale,
+	}).Error
+}
+
+type UserFilter struct {
+	Role      domain.UserRole
+	Suspended *bool
+	Query     string // Matches name or email
+	Limit     int
+	Offset    int
+}
+
+// List returns users for the admin console, newest first. Soft-deleted users are excluded.
+func (r *UserRepository) List(filter UserFilter) ([]domain.User, error) {
+	query := r.db.Model(&domain.User{})
+	if filter.Role != "" {
+		query = query.Where(hasRole, filter.Role)
+	}
+	if filter.Suspended != nil {
+		if *filter.Suspended {
+			query = query.Where("suspended_at IS NOT NULL")
+		} else {
+			query = query.Where("suspended_at IS NULL")
+		}
+	}
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ?", like, like)
+	}
+
+	var users []domain.User
+	err := query.Order("created_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&users).Error
+	return users, err
+}
+
+// UpdateRoles replaces only the roles, inside tx when given
+func (r *UserRepository) UpdateRoles(tx *gorm.DB, id uuid.UUID, roles domain.UserRoles) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&domain.User{}).Where("id = ?", id).Update("roles", roles).Error
+}
+
+// SetSuspendedAt suspends the user at the given time, or lifts the suspension when nil
+func (r *UserRepository) SetSuspendedAt(id uuid.UUID, at *time.Time) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", id).Update("suspended_at", at).Error
+}
+
+// UpdatePassword replaces only the password hash, inside tx when given
+func (r *UserRepository) UpdatePassword(tx *gorm.DB, id uuid.UUID, hash string) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&domain.User{}).Where("id = ?", id).Update("password", hash).Error
+}
+
+// MarkEmailVerified records when the user proved they own their address; a second
+// verification keeps the first time
+func (r *UserRepository) MarkEmailVerified(tx *gorm.DB, id uuid.UUID, at time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&domain.User{}).Where("id = ? AND email_verified_at IS NULL", id).Update("email_verified_at", at).Error
+}
+
+// Delete soft-deletes the user through DeletedAt; lookups stop finding them
+func (r *UserRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&domain.User{}, "id = ?", id).Error
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"appointment-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WaitlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWaitlistRepository(db *gorm.DB) *WaitlistRepository {
+	return &WaitlistRepository{db: db}
+}
+
+func (r *WaitlistRepository) Create(entry *domain.WaitlistEntry) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *WaitlistRepository) FindByID(id uuid.UUID) (*domain.WaitlistEntry, error) {
+	var entry domain.WaitlistEntry
+	err := r.db.First(&entry, "id = ?", id).Error
+	return &entry, err
+}
+
+// Update saves the entry's details. Status is left alone; it only moves through
+// CompareAndSetStatus so concurrent offers, claims and expiries can't clobber each other.
+func (r *WaitlistRepository) Update(entry *domain.WaitlistEntry) error {
+	return r.db.Omit("status").Save(entry).Error
+}
+
+// ListByCustomer returns the customer's open (waiting or offered) entries
+func (r *WaitlistRepository) ListByCustomer(customerID uuid.UUID) ([]domain.WaitlistEntry, error) {
+	var entries []domain.WaitlistEntry
+	err := r.db.Where("customer_id = ?", customerID).
+		Where("status IN ?", []domain.WaitlistStatus{domain.WaitlistWaiting, domain.WaitlistOffered}).
+		Order("date asc, created_at asc").
+		Find(&entries).Error
+	return entries, err
+}
+
+// ExistsOpen reports whether the customer is already queued for the same provider/service/date
+func (r *WaitlistRepository) ExistsOpen(customerID, providerID, serviceID uuid.UUID, date time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.WaitlistEntry{}).
+		Where("customer_id = ? AND provider_id = ? AND service_id = ? AND date = ?", customerID, providerID, serviceID, date.Format("2006-01-02")).
+		Where("status IN ?", []domain.WaitlistStatus{domain.WaitlistWaiting, domain.WaitlistOffered}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListWaiting returns the queue for a provider/date, first come first served
+func (r *WaitlistRepository) ListWaiting(providerID uuid.UUID, date time.Time) ([]domain.WaitlistEntry, error) {
+	var entries []domain.WaitlistEntry
+	err := r.db.Where("provider_id = ? AND date = ? AND status = ?", providerID, date.Format("2006-01-02"), domain.WaitlistWaiting).
+		Order("created_at asc").
+		Find(&entries).Error
+	return entries, err
+}
+
+// ListExpiredOffers returns offers whose time ran out
+func (r *WaitlistRepository) ListExpiredOffers(now time.Time) ([]domain.WaitlistEntry, error) {
+	var entries []domain.WaitlistEntry
+	err := r.db.Where("status = ? AND offer_expires_at < ?", domain.WaitlistOffered, now).
+		Find(&entries).Error
+	return entries, err
+}
+
+// CompareAndSetStatus moves the entry from `from` to `to` only if it is still in `from`.
+// It returns false if another request or instance got there first.
+func (r *WaitlistRepository) CompareAndSetStatus(id uuid.UUID, from, to domain.WaitlistStatus) (bool, error) {
+	res := r.db.Model(&domain.WaitlistEntry{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return res.RowsAffected == 1, res.Error
+}
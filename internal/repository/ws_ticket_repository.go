@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"appointment-booking/internal/domain"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// WSTicket is what a WebSocket connect ticket stands for: one session of one user
+type WSTicket struct {
+	UserID    uuid.UUID        `json:"user_id"`
+	SessionID uuid.UUID        `json:"session_id"`
+	Roles     domain.UserRoles `json:"roles"`
+}
+
+// WSTicketRepository stores short-lived, single-use WebSocket connect tickets. Browsers
+// can't set headers on a WebSocket handshake, so the credential has to go in the URL,
+// where access logs and proxies see it; a ticket is worthless once used or expired.
+type WSTicketRepository struct {
+	redis *redis.Client
+}
+
+func NewWSTicketRepository(redis *redis.Client) *WSTicketRepository {
+	return &WSTicketRepository{redis: redis}
+}
+
+func wsTicketKey(ticket string) string {
+	return fmt.Sprintf("ws_ticket:%s", ticket)
+}
+
+// Create stores the ticket for ttl and returns its random ID
+func (r *WSTicketRepository) Create(ctx context.Context, ticket *WSTicket, ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	id := base64.RawURLEncoding.EncodeToString(b)
+
+	data, err := json.Marshal(ticket)
+	if err != nil {
+		return "", err
+	}
+	if err := r.redis.Set(ctx, wsTicketKey(id), data, ttl).Err(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Consume returns the ticket and deletes it in one step, so it works only once.
+// It returns redis.Nil for an unknown, used or expired ticket.
+func (r *WSTicketRepository) Consume(ctx context.Context, id string) (*WSTicket, error) {
+	data, err := r.redis.GetDel(ctx, wsTicketKey(id)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var ticket WSTicket
+	if err := json.Unmarshal(data, &ticket); err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
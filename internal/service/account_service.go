@@ -0,0 +1,223 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/event"
+	"appointment-booking/internal/repository"
+	"appointment-booking/pkg/utils"
+	"errors"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidAccountToken  = errors.New("invalid or expired link")
+	ErrEmailAlreadyVerified = errors.New("email address is already verified")
+)
+
+// AccountService handles the emailed account links: verifying the address after
+// sign-up and resetting a forgotten password. Each link is a signed token naming an
+// AccountToken row, which makes it single-use; sending a new link of the same kind
+// invalidates the previous ones.
+type AccountService struct {
+	repo      *repository.UserRepository
+	tokenRepo *repository.AccountTokenRepository
+	notifier  *NotificationService
+	auth      *AuthService
+	baseURL   string
+	verifyTTL time.Duration
+	resetTTL  time.Duration
+}
+
+func NewAccountService(repo *repository.UserRepository, tokenRepo *repository.AccountTokenRepository, notifier *NotificationService, auth *AuthService, baseURL string, verifyTTL, resetTTL time.Duration) *AccountService {
+	return &AccountService{
+		repo:      repo,
+		tokenRepo: tokenRepo,
+		notifier:  notifier,
+		auth:      auth,
+		baseURL:   baseURL,
+		verifyTTL: verifyTTL,
+		resetTTL:  resetTTL,
+	}
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Subscribe sends the verification email to every new account
+func (s *AccountService) Subscribe(bus *event.Bus) {
+	event.On(bus, func(e event.UserRegistered) {
+		if err := s.SendVerification(e.UserID); err != nil {
+			log.Printf("Failed to send verification email to user %s: %v", e.UserID, err)
+		}
+	})
+}
+
+// SendVerification emails the user a fresh verification link
+func (s *AccountService) SendVerification(userID uuid.UUID) error {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+	return s.sendLink(user, domain.PurposeVerifyEmail, EventVerifyEmail, "/verify-email", s.verifyTTL)
+}
+
+// ForgotPassword emails a reset link if the address belongs to an active account. It
+// reports success either way, so the endpoint can't be used to find out who has an account.
+func (s *AccountService) ForgotPassword(input ForgotPasswordInput) error {
+	user, err := s.repo.FindByEmail(input.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.SuspendedAt != nil {
+		return nil
+	}
+	return s.sendLink(user, domain.PurposePasswordReset, EventPasswordReset, "/reset-password", s.resetTTL)
+}
+
+// ResetPassword sets the new password and signs the user out everywhere. The link also
+// proves the user reads the address, so it verifies the email if that was still pending.
+// The password is only hashed once redeem has accepted the token, so invalid tokens
+// can't be used to make the server burn bcrypt time.
+func (s *AccountService) ResetPassword(input ResetPasswordInput) error {
+	var userID uuid.UUID
+	err := s.redeem(input.Token, domain.PurposePasswordReset, func(tx *gorm.DB, token *domain.AccountToken, now time.Time) error {
+		hashedPwd, err := utils.HashPassword(input.Password)
+		if err != nil {
+			return err
+		}
+
+		userID = token.UserID
+		if err := s.repo.UpdatePassword(tx, token.UserID, hashedPwd); err != nil {
+			return err
+		}
+		return s.repo.MarkEmailVerified(tx, token.UserID, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Whoever knew the old password may still hold a session
+	return s.auth.LogoutAll(userID)
+}
+
+// VerifyEmail marks the address of the link's user as verified
+func (s *AccountService) VerifyEmail(input VerifyEmailInput) error {
+	return s.redeem(input.Token, domain.PurposeVerifyEmail, func(tx *gorm.DB, token *domain.AccountToken, now time.Time) error {
+		return s.repo.MarkEmailVerified(tx, token.UserID, now)
+	})
+}
+
+// sendLink records a new token for purpose, invalidating older ones, and queues the
+// email carrying the link in the same transaction
+func (s *AccountService) sendLink(user *domain.User, purpose domain.AccountTokenPurpose, notification NotificationEvent, path string, ttl time.Duration) error {
+	tx := s.tokenRepo.BeginTx()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	if err := s.tokenRepo.ConsumeAll(tx, user.ID, purpose, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	record := domain.AccountToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := s.tokenRepo.Create(tx, &record); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	token, err := utils.GenerateActionToken(string(purpose), user.ID, record.ID, ttl)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = s.notifier.Enqueue(tx, user.ID, notification, NotificationData{
+		Link:      s.baseURL + path + "?token=" + url.QueryEscape(token),
+		ExpiresAt: record.ExpiresAt,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// redeem checks the signed token against its record, runs apply and then uses up the
+// record together with any other open link of the same purpose, all in one transaction
+// holding the record's lock
+func (s *AccountService) redeem(signed string, purpose domain.AccountTokenPurpose, apply func(tx *gorm.DB, token *domain.AccountToken, now time.Time) error) error {
+	claims, err := utils.ValidateActionToken(signed, string(purpose))
+	if err != nil {
+		return ErrInvalidAccountToken
+	}
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return ErrInvalidAccountToken
+	}
+
+	tx := s.tokenRepo.BeginTx()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	token, err := s.tokenRepo.FindForUpdate(tx, tokenID)
+	if err != nil {
+		tx.Rollback()
+		return ErrInvalidAccountToken
+	}
+	if token.UserID != claims.UserID || token.Purpose != purpose || token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+		tx.Rollback()
+		return ErrInvalidAccountToken
+	}
+
+	// A deleted account no longer resolves; a suspended one can't reset its way back in
+	user, err := s.repo.FindByID(token.UserID)
+	if err != nil || (purpose == domain.PurposePasswordReset && user.SuspendedAt != nil) {
+		tx.Rollback()
+		return ErrInvalidAccountToken
+	}
+
+	if err := apply(tx, token, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := s.tokenRepo.ConsumeAll(tx, token.UserID, purpose, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"appointment-booking/internal/config"
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/event"
+	"appointment-booking/internal/repository"
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// parallelBookings is how many requests race for the same slot
+const parallelBookings = 10
+
+// testDB connects to the Postgres given by TEST_DB_URL and migrates it, or skips the test
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DB_URL")
+	if dsn == "" {
+		t.Skip("TEST_DB_URL not set, skipping Postgres-backed test")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Skipf("Postgres not reachable: %v", err)
+	}
+	if err := db.Exec("SELECT 1").Error; err != nil {
+		t.Skipf("Postgres not reachable: %v", err)
+	}
+	if err := config.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+type bookingFixture struct {
+	db        *gorm.DB
+	redis     *redis.Client
+	holdRepo  *repository.HoldRepository
+	service   *AppointmentService
+	provider  domain.User
+	offering  domain.Service
+	customers []domain.User
+}
+
+// newBookingFixture creates a provider offering one service plus n customers, and
+// removes everything it created when the test ends
+func newBookingFixture(t *testing.T, n int) *bookingFixture {
+	t.Helper()
+	db := testDB(t)
+
+	// Holds are advisory; without Redis the hold check logs and lets bookings through
+	redisAddr := os.Getenv("TEST_REDIS_ADDR")
+	if redisAddr == "" {
+		redisAddr = "localhost:6379"
+	}
+	redisClient := redis.NewClient(&redis.Options{Addr: redisAddr})
+	t.Cleanup(func() { redisClient.Close() })
+
+	f := &bookingFixture{db: db, redis: redisClient, holdRepo: repository.NewHoldRepository(redisClient)}
+	f.service = NewAppointmentService(
+		repository.NewAppointmentRepository(db),
+		repository.NewServiceRepository(db),
+		f.holdRepo,
+		repository.NewHistoryRepository(db),
+		repository.NewCancellationPolicyRepository(db),
+		event.NewBus(),
+	)
+
+	f.provider = testUser(t, db, domain.RoleProvider)
+	f.offering = domain.Service{Name: "Concurrency test", DurationMinutes: 30, Providers: []domain.User{f.provider}}
+	if err := db.Create(&f.offering).Error; err != nil {
+		t.Fatalf("create service: %v", err)
+	}
+	for i := 0; i < n; i++ {
+		f.customers = append(f.customers, testUser(t, db, domain.RoleCustomer))
+	}
+
+	t.Cleanup(func() {
+		db.Unscoped().Where("provider_id = ?", f.provider.ID).Delete(&domain.Appointment{})
+		db.Exec("DELETE FROM provider_services WHERE service_id = ?", f.offering.ID)
+		db.Unscoped().Delete(&f.offering)
+		db.Unscoped().Delete(&domain.User{ID: f.provider.ID})
+		for _, c := range f.customers {
+			db.Unscoped().Delete(&domain.User{ID: c.ID})
+		}
+	})
+	return f
+}
+
+// requireRedis skips the test unless the fixture's Redis is reachable, for tests where
+// holds must not silently degrade to advisory
+func (f *bookingFixture) requireRedis(t *testing.T) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := f.redis.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not reachable: %v", err)
+	}
+}
+
+func testUser(t *testing.T, db *gorm.DB, role domain.UserRole) domain.User {
+	t.Helper()
+	user := domain.User{
+		Name:     string(role),
+		Email:    uuid.NewString() + "@example.test",
+		Password: "x",
+		Roles:    domain.UserRoles{role},
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func (f *bookingFixture) actor(i int) domain.Actor {
+	return domain.Actor{ID: f.customers[i].ID, Roles: f.customers[i].Roles}
+}
+
+func (f *bookingFixture) input(start time.Time) BookingInput {
+	return BookingInput{
+		ProviderID: f.provider.ID.String(),
+		ServiceID:  f.offering.ID.String(),
+		StartTime:  start,
+	}
+}
+
+// race runs call for 0..n-1 at once and returns each result
+func race(n int, call func(i int) error) []error {
+	errs := make([]error, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = call(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+// assertOneWinner checks that exactly one call succeeded and every other lost with ErrSlotTaken
+func assertOneWinner(t *testing.T, errs []error) {
+	t.Helper()
+	wins := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			wins++
+		case !errors.Is(err, ErrSlotTaken):
+			t.Errorf("call %d: want ErrSlotTaken, got %v", i, err)
+		}
+	}
+	if wins != 1 {
+		t.Errorf("want exactly 1 successful call, got %d", wins)
+	}
+}
+
+func TestBookAppointmentParallelOnlyOneWins(t *testing.T) {
+	f := newBookingFixture(t, parallelBookings)
+	slot := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+
+	errs := race(parallelBookings, func(i int) error {
+		_, err := f.service.BookAppointment(f.actor(i), f.input(slot))
+		return err
+	})
+	assertOneWinner(t, errs)
+
+	var count int64
+	f.db.Model(&domain.Appointment{}).Where("provider_id = ?", f.provider.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("want 1 stored appointment, got %d", count)
+	}
+}
+
+func TestRescheduleAppointmentParallelOnlyOneWins(t *testing.T) {
+	f := newBookingFixture(t, parallelBookings)
+	base := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+
+	// Every customer starts on a slot of their own, then all move to the same one
+	ids := make([]uuid.UUID, parallelBookings)
+	for i := range ids {
+		appt, err := f.service.BookAppointment(f.actor(i), f.input(base.Add(time.Duration(i)*time.Hour)))
+		if err != nil {
+			t.Fatalf("seed booking %d: %v", i, err)
+		}
+		ids[i] = appt.ID
+	}
+	target := base.Add(-24 * time.Hour)
+
+	errs := race(parallelBookings, func(i int) error {
+		return f.service.RescheduleAppointment(ids[i], f.actor(i), target, "")
+	})
+	assertOneWinner(t, errs)
+
+	var count int64
+	f.db.Model(&domain.Appointment{}).Where("provider_id = ? AND start_time = ?", f.provider.ID, target).Count(&count)
+	if count != 1 {
+		t.Errorf("want 1 appointment at the target slot, got %d", count)
+	}
+}
+
+func TestHoldAndBookingRaceOnlyOneWins(t *testing.T) {
+	f := newBookingFixture(t, 2)
+	f.requireRedis(t)
+	holds := NewHoldService(f.holdRepo, repository.NewAppointmentRepository(f.db), f.service, time.Minute, 0)
+	base := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+
+	// Customer 0 holds while customer 1 books, on a fresh slot each round
+	for round := 0; round < parallelBookings; round++ {
+		slot := base.Add(time.Duration(round) * time.Hour)
+
+		var hold *domain.SlotHold
+		errs := race(2, func(i int) error {
+			if i == 0 {
+				h, err := holds.CreateHold(f.actor(0), f.input(slot))
+				hold = h
+				return err
+			}
+			_, err := f.service.BookAppointment(f.actor(1), f.input(slot))
+			return err
+		})
+		if hold != nil {
+			t.Cleanup(func() { f.holdRepo.Delete(context.Background(), hold) })
+		}
+
+		holdErr, bookErr := errs[0], errs[1]
+		switch {
+		case holdErr == nil && bookErr == nil:
+			t.Fatalf("round %d: both the hold and the booking succeeded", round)
+		case holdErr == nil && !errors.Is(bookErr, ErrSlotHeld):
+			t.Errorf("round %d: booking lost to the hold with %v, want ErrSlotHeld", round, bookErr)
+		case bookErr == nil && !errors.Is(holdErr, ErrSlotTaken):
+			t.Errorf("round %d: hold lost to the booking with %v, want ErrSlotTaken", round, holdErr)
+		case holdErr != nil && bookErr != nil:
+			t.Errorf("round %d: both failed: hold %v, booking %v", round, holdErr, bookErr)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/repository"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// AppointmentQuery is bound from the query string of GET /api/appointments
+type AppointmentQuery struct {
+	Status     string `form:"status"`      // Comma separated, e.g. "PENDING,CONFIRMED"
+	From       string `form:"from"`        // RFC3339, inclusive
+	To         string `form:"to"`          // RFC3339, exclusive
+	ProviderID string `form:"provider_id"` // Ignored for providers
+	CustomerID string `form:"customer_id"` // Ignored for customers
+	ServiceID  string `form:"service_id"`
+	Sort       string `form:"sort"`   // start_time (default), -start_time, created_at, -created_at
+	Cursor     string `form:"cursor"` // next_cursor from the previous page
+	Limit      int    `form:"limit" binding:"omitempty,min=1"`
+}
+
+type AppointmentPage struct {
+	Appointments []domain.Appointment `json:"appointments"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
+}
+
+// pageCursor is the opaque, base64-encoded position of the last row of a page
+type pageCursor struct {
+	Sort  string    `json:"s"`
+	Value time.Time `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// ListAppointments returns one page of appointments visible to the actor: their own
+// bookings and calendar, or everything with appointments:read_all.
+func (s *AppointmentService) ListAppointments(actor domain.Actor, query AppointmentQuery) (*AppointmentPage, error) {
+	filter, err := buildAppointmentFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Scope by permission
+	if !actor.Can(domain.PermReadAllAppointments) {
+		filter.ParticipantID = &actor.ID
+	}
+
+	// Fetch one extra row to know whether another page exists
+	pageSize := filter.Limit
+	filter.Limit = pageSize + 1
+
+	appointments, err := s.repo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &AppointmentPage{Appointments: appointments}
+	if len(appointments) > pageSize {
+		page.Appointments = appointments[:pageSize]
+		last := page.Appointments[pageSize-1]
+
+		value := last.StartTime
+		if filter.SortField == "created_at" {
+			value = last.CreatedAt
+		}
+		page.NextCursor = encodeCursor(pageCursor{Sort: query.sortKey(), Value: value, ID: last.ID})
+	}
+
+	return page, nil
+}
+
+// GetAppointment returns one appointment to its customer, its provider, or anyone
+// allowed to read all appointments
+func (s *AppointmentService) GetAppointment(appointmentID uuid.UUID, actor domain.Actor) (*domain.Appointment, error) {
+	appt, err := s.repo.FindByID(appointmentID)
+	if err != nil {
+		return nil, errors.New("appointment not found")
+	}
+
+	if !actor.Can(domain.PermReadAllAppointments) && appt.CustomerID != actor.ID && appt.ProviderID != actor.ID {
+		return nil, errors.New("unauthorized to view this appointment")
+	}
+
+	return appt, nil
+}
+
+func (q AppointmentQuery) sortKey() string {
+	if q.Sort == "" {
+		return "start_time"
+	}
+	return q.Sort
+}
+
+func buildAppointmentFilter(query AppointmentQuery) (repository.AppointmentFilter, error) {
+	var filter repository.AppointmentFilter
+
+	// Sort (whitelisted; the column name ends up in SQL)
+	sortKey := query.sortKey()
+	switch strings.TrimPrefix(sortKey, "-") {
+	case "start_time", "created_at":
+		filter.SortField = strings.TrimPrefix(sortKey, "-")
+		filter.Desc = strings.HasPrefix(sortKey, "-")
+	default:
+		return filter, errors.New("invalid sort (use start_time, -start_time, created_at or -created_at)")
+	}
+
+	// Page size
+	filter.Limit = query.Limit
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+
+	// Statuses
+	if query.Status != "" {
+		for _, raw := range strings.Split(query.Status, ",") {
+			status := domain.AppointmentStatus(strings.ToUpper(strings.TrimSpace(raw)))
+			if !status.IsValid() {
+				return filter, errors.New("invalid status: " + raw)
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	// Date range
+	if query.From != "" {
+		from, err := time.Parse(time.RFC3339, query.From)
+		if err != nil {
+			return filter, errors.New("invalid from time (use RFC3339)")
+		}
+		filter.From = &from
+	}
+	if query.To != "" {
+		to, err := time.Parse(time.RFC3339, query.To)
+		if err != nil {
+			return filter, errors.New("invalid to time (use RFC3339)")
+		}
+		filter.To = &to
+	}
+
+	// IDs
+	var err error
+	if filter.ProviderID, err = parseOptionalUUID(query.ProviderID, "invalid provider ID"); err != nil {
+		return filter, err
+	}
+	if filter.CustomerID, err = parseOptionalUUID(query.CustomerID, "invalid customer ID"); err != nil {
+		return filter, err
+	}
+	if filter.ServiceID, err = parseOptionalUUID(query.ServiceID, "invalid service ID"); err != nil {
+		return filter, err
+	}
+
+	// Cursor
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil || cursor.Sort != sortKey {
+			return filter, errors.New("invalid cursor")
+		}
+		filter.AfterValue = &cursor.Value
+		filter.AfterID = cursor.ID
+	}
+
+	return filter, nil
+}
+
+func parseOptionalUUID(raw, message string) (*uuid.UUID, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, errors.New(message)
+	}
+	return &id, nil
+}
+
+func encodeCursor(c pageCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (pageCursor, error) {
+	var c pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
@@ -2,53 +2,71 @@ package service
 
 import (
 	"appointment-booking/internal/domain"
+	"appointment-booking/internal/event"
 	"appointment-booking/internal/repository"
-	"appointment-booking/internal/websocket"
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/redis/go-redis/v9"
 )
 
+var (
+	// ErrSlotTaken is returned when the requested time collides with another appointment
+	ErrSlotTaken = repository.ErrSlotTaken
+	// ErrSlotHeld is returned when another customer is holding the requested time
+	ErrSlotHeld = repository.ErrHoldConflict
+	// ErrInvalidTransition is returned for any status change the transition table forbids
+	ErrInvalidTransition = errors.New("invalid status transition")
+)
+
+// AppointmentService owns the booking rules. Everything that should happen because of a
+// change (audit, reminders, notifications, cache, live updates, ...) subscribes to the
+// events it publishes on the bus.
 type AppointmentService struct {
-	repo      *repository.AppointmentRepository
-	notifier  *NotificationService
-	wsHandler *websocket.Handler
-	redis     *redis.Client
+	repo        *repository.AppointmentRepository
+	serviceRepo *repository.ServiceRepository
+	holdRepo    *repository.HoldRepository
+	historyRepo *repository.HistoryRepository
+	policyRepo  *repository.CancellationPolicyRepository
+	bus         *event.Bus
 }
 
-func NewAppointmentService(repo *repository.AppointmentRepository, notifier *NotificationService, ws *websocket.Handler, redis *redis.Client) *AppointmentService {
+func NewAppointmentService(repo *repository.AppointmentRepository, serviceRepo *repository.ServiceRepository, holdRepo *repository.HoldRepository, historyRepo *repository.HistoryRepository, policyRepo *repository.CancellationPolicyRepository, bus *event.Bus) *AppointmentService {
 	return &AppointmentService{
-		repo:      repo,
-		notifier:  notifier,
-		wsHandler: ws,
-		redis:     redis,
+		repo:        repo,
+		serviceRepo: serviceRepo,
+		holdRepo:    holdRepo,
+		historyRepo: historyRepo,
+		policyRepo:  policyRepo,
+		bus:         bus,
 	}
 }
 
+var ErrForbidden = errors.New("you do not have permission to do this")
+
+// The end time is derived from the service duration
 type BookingInput struct {
-	ProviderID  string    `json:"provider_id" binding:"required"`
-	ServiceType string    `json:"service_type" binding:"required"`
-	StartTime   time.Time `json:"start_time" binding:"required"`
-	EndTime     time.Time `json:"end_time" binding:"required"`
+	ProviderID string    `json:"provider_id" binding:"required"`
+	ServiceID  string    `json:"service_id" binding:"required"`
+	StartTime  time.Time `json:"start_time" binding:"required"`
+	CustomerID string    `json:"customer_id"` // Book on behalf of this customer; needs appointments:book_for
 }
 
-func (s *AppointmentService) BookAppointment(customerID uuid.UUID, input BookingInput) (*domain.Appointment, error) {
-	// 1. Validate Time
-	if input.EndTime.Before(input.StartTime) {
-		return nil, errors.New("end time must be after start time")
-	}
-	if input.StartTime.Before(time.Now()) {
-		return nil, errors.New("cannot book appointments in the past")
+func (s *AppointmentService) BookAppointment(actor domain.Actor, input BookingInput) (*domain.Appointment, error) {
+	customerID, err := bookingCustomer(actor, input)
+	if err != nil {
+		return nil, err
 	}
 
-	providerUUID, err := uuid.Parse(input.ProviderID)
+	// 1. Validate & Build
+	appointment, err := s.prepareBooking(customerID, input)
 	if err != nil {
-		return nil, errors.New("invalid provider ID")
+		return nil, err
 	}
+	providerUUID := appointment.ProviderID
 
 	// 2. Start Transaction
 	tx := s.repo.BeginTx()
@@ -59,28 +77,38 @@ func (s *AppointmentService) BookAppointment(customerID uuid.UUID, input Booking
 		}
 	}()
 
-	// 3. Check for Overlaps
-	hasOverlap, err := s.repo.HasOverlap(providerUUID, input.StartTime, input.EndTime)
+	// 3. Check for Holds & Overlaps
+	// Locking the provider row serializes concurrent bookings and holds for this provider,
+	// so the checks below see every committed appointment and every hold placed before
+	// us. The exclusion constraint is the backstop.
+	if err := s.repo.LockProvider(tx, providerUUID); err != nil {
+		tx.Rollback()
+		return nil, errors.New("provider not found")
+	}
+
+	if err := s.checkHolds(customerID, providerUUID, appointment.BlockStart, appointment.BlockEnd); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	hasOverlap, err := s.repo.HasOverlap(tx, providerUUID, appointment.BlockStart, appointment.BlockEnd, nil)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 	if hasOverlap {
 		tx.Rollback()
-		return nil, errors.New("time slot is not available")
+		return nil, repository.ErrSlotTaken
 	}
 
 	// 4. Create Appointment
-	appointment := &domain.Appointment{
-		CustomerID:  customerID,
-		ProviderID:  providerUUID,
-		ServiceType: input.ServiceType,
-		StartTime:   input.StartTime,
-		EndTime:     input.EndTime,
-		Status:      domain.StatusPending,
+	if err := s.repo.Create(tx, appointment); err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 
-	if err := s.repo.Create(tx, appointment); err != nil {
+	booked := event.AppointmentBooked{AppointmentEvent: event.AppointmentEvent{Appointment: appointment, Actor: actor}}
+	if err := s.bus.PublishTx(tx, booked); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
@@ -90,89 +118,415 @@ func (s *AppointmentService) BookAppointment(customerID uuid.UUID, input Booking
 		return nil, err
 	}
 
-	s.notifier.SendAsync(customerID, "Your appointment is confirmed!")
-	s.notifier.SendAsync(appointment.ProviderID, "You have a new booking!")
+	s.bus.Publish(booked)
+	return appointment, nil
+}
 
-	// 2. Push Real-time Update (Sync/Non-blocking via channel)
-	s.wsHandler.Broadcast(map[string]interface{}{
-		"event":       "new_booking",
-		"provider_id": appointment.ProviderID,
-		"slot":        appointment.StartTime,
-	})
+// bookingCustomer decides whom the appointment is for: the actor, or the customer in
+// the input when the actor may book on behalf of others
+func bookingCustomer(actor domain.Actor, input BookingInput) (uuid.UUID, error) {
+	if input.CustomerID == "" || input.CustomerID == actor.ID.String() {
+		if !actor.Can(domain.PermBookAppointments) {
+			return uuid.Nil, ErrForbidden
+		}
+		return actor.ID, nil
+	}
 
-	// Invalidate Cache for that Provider + Date
-	dateStr := input.StartTime.Format("2006-01-02")
-	cacheKey := fmt.Sprintf("slots:%s:%s", appointment.ProviderID.String(), dateStr)
+	if !actor.Can(domain.PermBookForCustomers) {
+		return uuid.Nil, ErrForbidden
+	}
+	customerID, err := uuid.Parse(input.CustomerID)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid customer ID")
+	}
+	return customerID, nil
+}
 
-	// We ignore errors here; if Redis is down, it just means cache expires naturally later
-	s.redis.Del(context.Background(), cacheKey)
+// prepareBooking validates the input and builds the (unsaved) appointment,
+// deriving the end time and blocked range from the service
+func (s *AppointmentService) prepareBooking(customerID uuid.UUID, input BookingInput) (*domain.Appointment, error) {
+	if input.StartTime.Before(time.Now()) {
+		return nil, errors.New("cannot book appointments in the past")
+	}
 
-	return appointment, nil
+	providerUUID, err := uuid.Parse(input.ProviderID)
+	if err != nil {
+		return nil, errors.New("invalid provider ID")
+	}
+
+	serviceUUID, err := uuid.Parse(input.ServiceID)
+	if err != nil {
+		return nil, errors.New("invalid service ID")
+	}
+
+	svc, err := s.serviceRepo.FindByID(serviceUUID)
+	if err != nil {
+		return nil, errors.New("service not found")
+	}
+	offered, err := s.serviceRepo.IsOfferedBy(svc.ID, providerUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !offered {
+		return nil, errors.New("provider does not offer this service")
+	}
+
+	endTime := input.StartTime.Add(svc.Duration())
+
+	return &domain.Appointment{
+		CustomerID:  customerID,
+		ProviderID:  providerUUID,
+		ServiceID:   &svc.ID,
+		ServiceType: svc.Name,
+		StartTime:   input.StartTime,
+		EndTime:     endTime,
+		BlockStart:  input.StartTime.Add(-svc.BufferBefore()),
+		BlockEnd:    endTime.Add(svc.BufferAfter()),
+		Status:      domain.StatusPending,
+	}, nil
+}
+
+// checkHolds rejects a range that overlaps another customer's live slot hold. Call it
+// with the provider locked, which is what CreateHold takes before placing a hold.
+// Holds are advisory: if Redis is unreachable we log and let the booking through.
+func (s *AppointmentService) checkHolds(customerID, providerID uuid.UUID, blockStart, blockEnd time.Time) error {
+	holds, err := s.holdRepo.ListActive(context.Background(), providerID)
+	if err != nil {
+		log.Printf("Hold lookup failed for provider %s: %v", providerID, err)
+		return nil
+	}
+
+	for _, h := range holds {
+		if h.CustomerID != customerID && blockStart.Before(h.BlockEnd) && blockEnd.After(h.BlockStart) {
+			return ErrSlotHeld
+		}
+	}
+	return nil
 }
 
-func (s *AppointmentService) CancelAppointment(appointmentID, userID uuid.UUID) error {
+// CancelAppointment cancels on behalf of the customer or the provider, enforcing the
+// provider's cancellation policy for whichever side is cancelling
+func (s *AppointmentService) CancelAppointment(appointmentID uuid.UUID, actor domain.Actor, input CancelInput) error {
+	tx := s.repo.BeginTx()
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
 	// 1. Fetch Appointment
-	appt, err := s.repo.FindByID(appointmentID)
+	appt, err := s.repo.FindByIDForUpdate(tx, appointmentID)
+	if err != nil {
+		tx.Rollback()
+		return errors.New("appointment not found")
+	}
+
+	// 2. Authorization Check: the customer, the provider, or someone who manages all appointments
+	if !canManage(actor, appt) {
+		tx.Rollback()
+		return ErrForbidden
+	}
+
+	// 3. Policy Check (customer rules win if someone booked with themselves). Staff and
+	// admins cancel for the business, so the provider rules apply to them.
+	policy, err := s.policyRepo.FindByProvider(tx, appt.ProviderID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	side, rule := domain.RoleCustomer, policy.Customer
+	if appt.CustomerID != actor.ID {
+		side, rule = domain.RoleProvider, policy.Provider
+	}
+	if appt.CustomerID != actor.ID && appt.ProviderID != actor.ID {
+		side = actor.Roles.Primary()
+	}
+
+	now := time.Now()
+	late, err := evaluateCancellation(rule, appt, input, now)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 4. State Validation & Update
+	oldStatus := appt.Status
+	if err := transition(appt, domain.StatusCancelled); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	appt.CancelledAt = &now
+	appt.CancelledBy = side
+	appt.CancellationReason = domain.CancellationReason(input.ReasonCode)
+	appt.CancellationNote = input.Reason
+	appt.LateCancellation = late
+
+	if err := s.repo.Update(tx, appt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	cancelled := event.AppointmentCancelled{
+		AppointmentEvent: event.AppointmentEvent{Appointment: appt, Actor: actor, OldStatus: oldStatus, Reason: cancellationSummary(input)},
+		Note:             input.Reason,
+	}
+	if err := s.bus.PublishTx(tx, cancelled); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	s.bus.Publish(cancelled)
+	return nil
+}
+
+// cancellationSummary renders the reason for the audit trail, e.g. "ILLNESS: flu"
+func cancellationSummary(input CancelInput) string {
+	switch {
+	case input.ReasonCode != "" && input.Reason != "":
+		return input.ReasonCode + ": " + input.Reason
+	case input.ReasonCode != "":
+		return input.ReasonCode
+	default:
+		return input.Reason
+	}
+}
+
+// ConfirmAppointment lets the provider accept a pending booking
+func (s *AppointmentService) ConfirmAppointment(appointmentID uuid.UUID, actor domain.Actor, reason string) error {
+	return s.providerTransition(appointmentID, actor, domain.StatusConfirmed, reason, nil)
+}
+
+// DeclineAppointment lets the provider reject a pending booking, freeing the slot
+func (s *AppointmentService) DeclineAppointment(appointmentID uuid.UUID, actor domain.Actor, reason string) error {
+	return s.providerTransition(appointmentID, actor, domain.StatusCancelled, reason, func(appt *domain.Appointment) error {
+		if appt.Status != domain.StatusPending {
+			return fmt.Errorf("%w: only pending appointments can be declined", ErrInvalidTransition)
+		}
+		return nil
+	})
+}
+
+// MarkNoShow records that the customer did not turn up
+func (s *AppointmentService) MarkNoShow(appointmentID uuid.UUID, actor domain.Actor, reason string) error {
+	return s.providerTransition(appointmentID, actor, domain.StatusNoShow, reason, requireStarted)
+}
+
+// CompleteAppointment records that the appointment took place
+func (s *AppointmentService) CompleteAppointment(appointmentID uuid.UUID, actor domain.Actor, reason string) error {
+	return s.providerTransition(appointmentID, actor, domain.StatusCompleted, reason, requireStarted)
+}
+
+// providerTransition applies a status change that only the appointment's provider (or
+// someone who manages all appointments) may make. guard, if set, adds action-specific
+// rules on top of the transition table.
+func (s *AppointmentService) providerTransition(appointmentID uuid.UUID, actor domain.Actor, next domain.AppointmentStatus, reason string, guard func(*domain.Appointment) error) error {
+	tx := s.repo.BeginTx()
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	appt, err := s.repo.FindByIDForUpdate(tx, appointmentID)
 	if err != nil {
+		tx.Rollback()
 		return errors.New("appointment not found")
 	}
 
-	// 2. Authorization Check (Is this the user's appointment?)
-	// In a real app, Providers/Admins should also be able to cancel.
-	if appt.CustomerID != userID && appt.ProviderID != userID {
+	serves := appt.ProviderID == actor.ID && actor.Can(domain.PermServeAppointments)
+	if !serves && !actor.Can(domain.PermManageAppointments) {
+		tx.Rollback()
 		return errors.New("unauthorized to modify this appointment")
 	}
 
-	// 3. State Validation
-	if appt.Status == domain.StatusCompleted {
-		return errors.New("cannot cancel a completed appointment")
+	if guard != nil {
+		if err := guard(appt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	oldStatus := appt.Status
+	if err := transition(appt, next); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if next == domain.StatusCancelled {
+		now := time.Now()
+		appt.CancelledAt = &now
+		appt.CancelledBy = domain.RoleProvider
+		if appt.ProviderID != actor.ID {
+			appt.CancelledBy = actor.Roles.Primary()
+		}
+		appt.CancellationNote = reason
+	}
+
+	if err := s.repo.Update(tx, appt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	base := event.AppointmentEvent{Appointment: appt, Actor: actor, OldStatus: oldStatus, Reason: reason}
+	var changed event.Event = event.AppointmentStatusChanged{AppointmentEvent: base}
+	if next == domain.StatusCancelled {
+		changed = event.AppointmentCancelled{AppointmentEvent: base, Note: reason}
+	}
+	if err := s.bus.PublishTx(tx, changed); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	s.bus.Publish(changed)
+	return nil
+}
+
+// canManage reports whether the actor may cancel or reschedule the appointment: its
+// customer while allowed to book, its provider while allowed to serve, or anyone
+// allowed to manage all appointments. Owning the appointment alone is not enough, so
+// losing a role takes the right away.
+func canManage(actor domain.Actor, appt *domain.Appointment) bool {
+	switch {
+	case actor.Can(domain.PermManageAppointments):
+		return true
+	case appt.CustomerID == actor.ID && actor.Can(domain.PermBookAppointments):
+		return true
+	default:
+		return appt.ProviderID == actor.ID && actor.Can(domain.PermServeAppointments)
 	}
-	if appt.Status == domain.StatusCancelled {
-		return errors.New("appointment is already cancelled")
+}
+
+// transition moves appt to next if the transition table allows it
+func transition(appt *domain.Appointment, next domain.AppointmentStatus) error {
+	if !appt.Status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: cannot move appointment from %s to %s", ErrInvalidTransition, appt.Status, next)
 	}
+	appt.Status = next
+	return nil
+}
 
-	// 4. Update Status
-	appt.Status = domain.StatusCancelled
-	return s.repo.Update(appt)
+func requireStarted(appt *domain.Appointment) error {
+	if time.Now().Before(appt.StartTime) {
+		return fmt.Errorf("%w: appointment has not started yet", ErrInvalidTransition)
+	}
+	return nil
 }
 
-func (s *AppointmentService) RescheduleAppointment(appointmentID, userID uuid.UUID, newStart, newEnd time.Time) error {
-	// 1. Fetch & Validate Ownership
-	appt, err := s.repo.FindByID(appointmentID)
+// RescheduleAppointment moves an appointment to newStart, keeping its duration and buffers
+func (s *AppointmentService) RescheduleAppointment(appointmentID uuid.UUID, actor domain.Actor, newStart time.Time, reason string) error {
+	// 1. Validate New Time
+	if newStart.Before(time.Now()) {
+		return errors.New("cannot reschedule into the past")
+	}
+
+	tx := s.repo.BeginTx()
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 2. Fetch & Authorize (row locked so a concurrent cancel/reschedule waits)
+	appt, err := s.repo.FindByIDForUpdate(tx, appointmentID)
 	if err != nil {
+		tx.Rollback()
 		return errors.New("appointment not found")
 	}
 
-	if appt.CustomerID != userID {
-		return errors.New("unauthorized")
+	if !canManage(actor, appt) {
+		tx.Rollback()
+		return ErrForbidden
 	}
 
-	if appt.Status == domain.StatusCancelled || appt.Status == domain.StatusCompleted {
-		return errors.New("cannot reschedule completed or cancelled appointments")
+	if !appt.Status.IsActive() {
+		tx.Rollback()
+		return fmt.Errorf("%w: cannot reschedule a %s appointment", ErrInvalidTransition, appt.Status)
 	}
 
-	// 2. Validate New Time
-	if newEnd.Before(newStart) {
-		return errors.New("invalid time range")
+	newEnd := newStart.Add(appt.EndTime.Sub(appt.StartTime))
+	newBlockStart := newStart.Add(-appt.StartTime.Sub(appt.BlockStart))
+	newBlockEnd := newEnd.Add(appt.BlockEnd.Sub(appt.EndTime))
+
+	// 3. Check Availability for the NEW time (ignoring this appointment's current slot)
+	if err := s.repo.LockProvider(tx, appt.ProviderID); err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	// 3. Check Availability for the NEW time
-	// Note: HasOverlap checks ALL appointments. It might flag THIS appointment as a conflict
-	// if the times overlap slightly.
-	// ideally, we should exclude the current appointment ID from the overlap check.
-	// For this phase, we assume the user is moving to a completely different slot.
-	hasOverlap, err := s.repo.HasOverlap(appt.ProviderID, newStart, newEnd)
+	if err := s.checkHolds(appt.CustomerID, appt.ProviderID, newBlockStart, newBlockEnd); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	hasOverlap, err := s.repo.HasOverlap(tx, appt.ProviderID, newBlockStart, newBlockEnd, &appt.ID)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 	if hasOverlap {
-		return errors.New("new time slot is not available")
+		tx.Rollback()
+		return repository.ErrSlotTaken
 	}
 
 	// 4. Update
+	oldStart := appt.StartTime
+	oldStatus := appt.Status
+	oldBlockStart, oldBlockEnd := appt.BlockStart, appt.BlockEnd
 	appt.StartTime = newStart
 	appt.EndTime = newEnd
-	appt.Status = domain.StatusConfirmed // Auto-confirm on reschedule? Business decision.
+	appt.BlockStart = newBlockStart
+	appt.BlockEnd = newBlockEnd
+
+	// The provider has to confirm the new time again
+	if appt.Status == domain.StatusConfirmed {
+		if err := transition(appt, domain.StatusPending); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := s.repo.Update(tx, appt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	rescheduled := event.AppointmentRescheduled{
+		AppointmentEvent: event.AppointmentEvent{Appointment: appt, Actor: actor, OldStatus: oldStatus, Reason: reason},
+		OldStartTime:     oldStart,
+		OldBlockStart:    oldBlockStart,
+		OldBlockEnd:      oldBlockEnd,
+	}
+	if err := s.bus.PublishTx(tx, rescheduled); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	s.bus.Publish(rescheduled)
+	return nil
+}
+
+// GetHistory returns the audit trail of one appointment to whoever may see the appointment
+func (s *AppointmentService) GetHistory(appointmentID uuid.UUID, actor domain.Actor) ([]domain.AppointmentHistory, error) {
+	if _, err := s.GetAppointment(appointmentID, actor); err != nil {
+		return nil, err
+	}
 
-	return s.repo.Update(appt)
+	return s.historyRepo.ListByAppointment(appointmentID)
 }
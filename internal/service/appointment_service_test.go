@@ -0,0 +1,57 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCanManageNeedsPermissionNotJustOwnership(t *testing.T) {
+	customerID, providerID := uuid.New(), uuid.New()
+	appt := &domain.Appointment{CustomerID: customerID, ProviderID: providerID}
+
+	cases := []struct {
+		name  string
+		actor domain.Actor
+		want  bool
+	}{
+		{"customer", domain.Actor{ID: customerID, Roles: domain.UserRoles{domain.RoleCustomer}}, true},
+		{"customer without a role", domain.Actor{ID: customerID}, false},
+		{"provider", domain.Actor{ID: providerID, Roles: domain.UserRoles{domain.RoleProvider}}, true},
+		{"provider who lost the role", domain.Actor{ID: providerID, Roles: domain.UserRoles{domain.RoleCustomer}}, false},
+		{"staff", domain.Actor{ID: uuid.New(), Roles: domain.UserRoles{domain.RoleStaff}}, true},
+		{"other customer", domain.Actor{ID: uuid.New(), Roles: domain.UserRoles{domain.RoleCustomer}}, false},
+	}
+	for _, tc := range cases {
+		if got := canManage(tc.actor, appt); got != tc.want {
+			t.Errorf("%s: canManage = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestOwnerWithoutPermissionCannotCancelOrReschedule(t *testing.T) {
+	f := newBookingFixture(t, 1)
+	start := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+
+	appt, err := f.service.BookAppointment(f.actor(0), f.input(start))
+	if err != nil {
+		t.Fatalf("book: %v", err)
+	}
+
+	// Same people, but the customer lost the customer role and the provider the provider role
+	owners := map[string]domain.Actor{
+		"customer": {ID: f.customers[0].ID},
+		"provider": {ID: f.provider.ID, Roles: domain.UserRoles{domain.RoleCustomer}},
+	}
+	for name, actor := range owners {
+		if err := f.service.CancelAppointment(appt.ID, actor, CancelInput{}); !errors.Is(err, ErrForbidden) {
+			t.Errorf("%s cancel: want ErrForbidden, got %v", name, err)
+		}
+		if err := f.service.RescheduleAppointment(appt.ID, actor, start.Add(time.Hour), ""); !errors.Is(err, ErrForbidden) {
+			t.Errorf("%s reschedule: want ErrForbidden, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/event"
+	"appointment-booking/internal/repository"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditService gives admins a search over the appointment audit trail
+type AuditService struct {
+	repo *repository.HistoryRepository
+}
+
+func NewAuditService(repo *repository.HistoryRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+type AuditQuery struct {
+	AppointmentID string `form:"appointment_id"`
+	ActorID       string `form:"actor_id"`
+	Action        string `form:"action"`
+	From          string `form:"from"` // RFC3339
+	To            string `form:"to"`   // RFC3339
+	Limit         int    `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset        int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+func (s *AuditService) Search(query AuditQuery) ([]domain.AppointmentHistory, error) {
+	filter := repository.AuditFilter{
+		Action: domain.HistoryAction(query.Action),
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 50
+	}
+
+	if query.AppointmentID != "" {
+		id, err := uuid.Parse(query.AppointmentID)
+		if err != nil {
+			return nil, errors.New("invalid appointment ID")
+		}
+		filter.AppointmentID = &id
+	}
+	if query.ActorID != "" {
+		id, err := uuid.Parse(query.ActorID)
+		if err != nil {
+			return nil, errors.New("invalid actor ID")
+		}
+		filter.ActorID = &id
+	}
+	if query.From != "" {
+		from, err := time.Parse(time.RFC3339, query.From)
+		if err != nil {
+			return nil, errors.New("invalid from time (use RFC3339)")
+		}
+		filter.From = &from
+	}
+	if query.To != "" {
+		to, err := time.Parse(time.RFC3339, query.To)
+		if err != nil {
+			return nil, errors.New("invalid to time (use RFC3339)")
+		}
+		filter.To = &to
+	}
+
+	return s.repo.Search(filter)
+}
+
+// Subscribe records every appointment change in the same transaction as the change
+func (s *AuditService) Subscribe(bus *event.Bus) {
+	event.OnTx(bus, func(tx *gorm.DB, e event.AppointmentBooked) error {
+		return s.record(tx, e.AppointmentEvent, domain.ActionCreated, nil)
+	})
+	event.OnTx(bus, func(tx *gorm.DB, e event.AppointmentCancelled) error {
+		return s.record(tx, e.AppointmentEvent, domain.ActionCancelled, nil)
+	})
+	event.OnTx(bus, func(tx *gorm.DB, e event.AppointmentRescheduled) error {
+		return s.record(tx, e.AppointmentEvent, domain.ActionRescheduled, &e.OldStartTime)
+	})
+	event.OnTx(bus, func(tx *gorm.DB, e event.AppointmentStatusChanged) error {
+		return s.record(tx, e.AppointmentEvent, domain.ActionStatusChanged, nil)
+	})
+}
+
+// record appends an audit entry. oldStart is nil when the start time didn't change.
+func (s *AuditService) record(tx *gorm.DB, e event.AppointmentEvent, action domain.HistoryAction, oldStart *time.Time) error {
+	newStart := e.Appointment.StartTime
+	entry := &domain.AppointmentHistory{
+		AppointmentID: e.Appointment.ID,
+		ActorID:       e.Actor.ID,
+		ActorRole:     e.Actor.Roles.Primary(),
+		Action:        action,
+		OldStatus:     e.OldStatus,
+		NewStatus:     e.Appointment.Status,
+		OldStartTime:  oldStart,
+		NewStartTime:  &newStart,
+		Reason:        e.Reason,
+	}
+	return s.repo.Create(tx, entry)
+}
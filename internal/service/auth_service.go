@@ -2,24 +2,65 @@ package service
 
 import (
 	"appointment-booking/internal/domain"
+	"appointment-booking/internal/event"
 	"appointment-booking/internal/repository"
 	"appointment-booking/pkg/utils"
+	"context"
 	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRoleNotAllowed      = errors.New("registration only creates customer accounts; apply to become a provider after signing in")
+	ErrAccountSuspended    = errors.New("account is suspended")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
 )
 
+// AuthService issues short-lived access tokens plus rotating refresh tokens. A login
+// starts a session; logging out or detecting a reused refresh token revokes the whole
+// session, both in the database and in the revocation list AuthMiddleware checks.
 type AuthService struct {
-	repo *repository.UserRepository
+	repo        *repository.UserRepository
+	tokenRepo   *repository.RefreshTokenRepository
+	revocations *repository.RevocationRepository
+	bus         *event.Bus
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
 }
 
-func NewAuthService(repo *repository.UserRepository) *AuthService {
-	return &AuthService{repo: repo}
+func NewAuthService(repo *repository.UserRepository, tokenRepo *repository.RefreshTokenRepository, revocations *repository.RevocationRepository, bus *event.Bus, accessTTL, refreshTTL time.Duration) *AuthService {
+	return &AuthService{
+		repo:        repo,
+		tokenRepo:   tokenRepo,
+		revocations: revocations,
+		bus:         bus,
+		accessTTL:   accessTTL,
+		refreshTTL:  refreshTTL,
+	}
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // Access token lifetime in seconds
+}
+
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 type RegisterInput struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
-	Role     string `json:"role"` // Optional, default to customer
+	Role     string `json:"role"`      // Optional; only "customer" is accepted
+	TimeZone string `json:"time_zone"` // Optional IANA name, default to UTC
+	Locale   string `json:"locale"`    // Optional notification language, default to English
 }
 
 type LoginInput struct {
@@ -28,48 +69,199 @@ type LoginInput struct {
 }
 
 func (s *AuthService) Register(input RegisterInput) error {
-	// 1. Hash Password
-	hashedPwd, err := utils.HashPassword(input.Password)
+	// 0. Public sign-up only creates customers. Providers go through an application
+	// reviewed by an admin, and only admins assign other roles.
+	if input.Role != "" && domain.UserRole(input.Role) != domain.RoleCustomer {
+		return ErrRoleNotAllowed
+	}
+
+	// Validate Time Zone
+	loc, err := loadLocation(input.TimeZone)
 	if err != nil {
 		return err
 	}
 
-	// 2. Set Default Role if empty
-	role := domain.RoleCustomer
-	if input.Role == "provider" {
-		role = domain.RoleProvider
+	locale := defaultLocale
+	if input.Locale != "" {
+		if !IsSupportedLocale(input.Locale) {
+			return ErrUnsupportedLocale
+		}
+		locale = input.Locale
 	}
-	// Security: Only admins should be able to create "admin" users via API.
-	// For now, we allow it for simplicity, but note this for interviews.
 
-	// 3. Create User
+	// 1. Hash Password
+	hashedPwd, err := utils.HashPassword(input.Password)
+	if err != nil {
+		return err
+	}
+
+	// 2. Create User
 	user := domain.User{
 		Name:     input.Name,
 		Email:    input.Email,
 		Password: hashedPwd,
-		Role:     role,
+		Roles:    domain.UserRoles{domain.RoleCustomer},
+		TimeZone: loc.String(),
+		Locale:   locale,
+	}
+
+	if err := s.repo.Create(&user); err != nil {
+		return err
 	}
 
-	return s.repo.Create(&user)
+	// 3. The verification email goes out from a subscriber
+	s.bus.Publish(event.UserRegistered{UserID: user.ID})
+	return nil
 }
 
-func (s *AuthService) Login(input LoginInput) (string, error) {
+func (s *AuthService) Login(input LoginInput) (*TokenPair, error) {
 	// 1. Find User
 	user, err := s.repo.FindByEmail(input.Email)
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		return nil, errors.New("invalid credentials")
 	}
 
 	// 2. Check Password
 	if !utils.CheckPassword(input.Password, user.Password) {
-		return "", errors.New("invalid credentials")
+		return nil, errors.New("invalid credentials")
 	}
+	if user.SuspendedAt != nil {
+		return nil, ErrAccountSuspended
+	}
+
+	// 3. Start a new session
+	return s.issue(nil, user, uuid.New())
+}
+
+// Refresh exchanges a refresh token for a new token pair in the same session. Each
+// refresh token works once; presenting one that was already rotated means a copy leaked,
+// so the whole session is revoked.
+func (s *AuthService) Refresh(input RefreshInput) (*TokenPair, error) {
+	tx := s.tokenRepo.BeginTx()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
 
-	// 3. Generate Token
-	token, err := utils.GenerateToken(user.ID, string(user.Role))
+	current, err := s.tokenRepo.FindByHashForUpdate(tx, utils.HashRefreshToken(input.RefreshToken))
 	if err != nil {
-		return "", err
+		tx.Rollback()
+		return nil, ErrInvalidRefreshToken
+	}
+	if current.RevokedAt != nil || !time.Now().Before(current.ExpiresAt) {
+		tx.Rollback()
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if current.UsedAt != nil {
+		if err := s.tokenRepo.RevokeSession(tx, current.SessionID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+		log.Printf("Refresh token reuse for user %s, revoking session %s", current.UserID, current.SessionID)
+		s.revokeAccess(current.SessionID)
+		return nil, ErrRefreshTokenReused
+	}
+
+	// The roles may have changed since the last refresh. Suspending or deleting a user
+	// revokes their sessions, so this only catches races with that.
+	user, err := s.repo.FindByID(current.UserID)
+	if err != nil || user.SuspendedAt != nil {
+		tx.Rollback()
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if err := s.tokenRepo.MarkUsed(tx, current.ID, time.Now()); err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 
-	return token, nil
+	pair, err := s.issue(tx, user, current.SessionID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Logout ends the session the refresh token belongs to
+func (s *AuthService) Logout(input RefreshInput) error {
+	token, err := s.tokenRepo.FindByHash(utils.HashRefreshToken(input.RefreshToken))
+	if err != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	if err := s.tokenRepo.RevokeSession(nil, token.SessionID); err != nil {
+		return err
+	}
+	return s.revoke(token.SessionID)
+}
+
+// LogoutAll ends every session of the user, on all devices
+func (s *AuthService) LogoutAll(userID uuid.UUID) error {
+	sessionIDs, err := s.tokenRepo.RevokeUser(nil, userID)
+	if err != nil {
+		return err
+	}
+	return s.revoke(sessionIDs...)
+}
+
+// issue creates an access token and a fresh refresh token for the session, inside tx when given
+func (s *AuthService) issue(tx *gorm.DB, user *domain.User, sessionID uuid.UUID) (*TokenPair, error) {
+	roles := make([]string, len(user.Roles))
+	for i, role := range user.Roles {
+		roles[i] = string(role)
+	}
+	accessToken, err := utils.GenerateToken(user.ID, roles, sessionID, user.EmailVerified(), s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, hash, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	err = s.tokenRepo.Create(tx, &domain.RefreshToken{
+		UserID:    user.ID,
+		SessionID: sessionID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.accessTTL.Seconds()),
+	}, nil
+}
+
+// revoke puts the sessions on the revocation list for AuthMiddleware and announces the
+// revocation, which closes their open WebSocket connections. The connections are closed
+// even if Redis fails, since the refresh tokens are already revoked in the database.
+func (s *AuthService) revoke(sessionIDs ...uuid.UUID) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	err := s.revocations.Revoke(context.Background(), s.accessTTL, sessionIDs...)
+	s.bus.Publish(event.SessionsRevoked{SessionIDs: sessionIDs})
+	return err
+}
+
+// revokeAccess revokes the session when there is no caller to report a failure to; a
+// Redis failure only leaves access tokens valid until they expire.
+func (s *AuthService) revokeAccess(sessionID uuid.UUID) {
+	if err := s.revoke(sessionID); err != nil {
+		log.Printf("Failed to revoke session %s: %v", sessionID, err)
+	}
 }
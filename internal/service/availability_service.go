@@ -2,11 +2,13 @@ package service
 
 import (
 	"appointment-booking/internal/domain"
+	"appointment-booking/internal/event"
 	"appointment-booking/internal/repository"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,39 +16,282 @@ import (
 )
 
 type AvailabilityService struct {
-	availRepo *repository.AvailabilityRepository
-	apptRepo  *repository.AppointmentRepository
-	redis     *redis.Client
+	availRepo   *repository.AvailabilityRepository
+	apptRepo    *repository.AppointmentRepository
+	serviceRepo *repository.ServiceRepository
+	userRepo    *repository.UserRepository
+	holdRepo    *repository.HoldRepository
+	bus         *event.Bus
+	redis       *redis.Client
 }
 
-func NewAvailabilityService(availRepo *repository.AvailabilityRepository, apptRepo *repository.AppointmentRepository, redis *redis.Client) *AvailabilityService {
-	return &AvailabilityService{availRepo: availRepo, apptRepo: apptRepo, redis: redis}
+func NewAvailabilityService(availRepo *repository.AvailabilityRepository, apptRepo *repository.AppointmentRepository, serviceRepo *repository.ServiceRepository, userRepo *repository.UserRepository, holdRepo *repository.HoldRepository, bus *event.Bus, redis *redis.Client) *AvailabilityService {
+	return &AvailabilityService{availRepo: availRepo, apptRepo: apptRepo, serviceRepo: serviceRepo, userRepo: userRepo, holdRepo: holdRepo, bus: bus, redis: redis}
 }
 
+// Slot size used when no service is requested
+const defaultSlotDuration = 30 * time.Minute
+
 type SetAvailabilityInput struct {
 	DayOfWeek int    `json:"day_of_week" binding:"required,min=0,max=6"`
 	StartTime string `json:"start_time" binding:"required"` // "09:00"
 	EndTime   string `json:"end_time" binding:"required"`   // "17:00"
 }
 
-// Allows a provider to define their schedule
-func (s *AvailabilityService) SetAvailability(providerID uuid.UUID, input SetAvailabilityInput) error {
+type AvailabilityOverrideInput struct {
+	Date      string `json:"date" binding:"required"` // "2025-12-24"
+	Type      string `json:"type" binding:"required,oneof=OPEN CLOSED HOLIDAY"`
+	StartTime string `json:"start_time"` // Required for OPEN, optional for CLOSED
+	EndTime   string `json:"end_time"`
+	Reason    string `json:"reason"`
+}
+
+// Weekly windows and overrides are wall-clock times in TimeZone
+type Schedule struct {
+	TimeZone  string                        `json:"time_zone"`
+	Weekly    []domain.Availability         `json:"weekly"`
+	Overrides []domain.AvailabilityOverride `json:"overrides"`
+}
+
+// Allows a provider to add a weekly working window. Several windows per day are
+// allowed as long as they don't overlap.
+func (s *AvailabilityService) SetAvailability(providerID uuid.UUID, input SetAvailabilityInput) (*domain.Availability, error) {
+	start, end, err := normalizeClockRange(input.StartTime, input.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.availRepo.GetByProviderAndDay(providerID, input.DayOfWeek)
+	if err != nil {
+		return nil, err
+	}
+	if overlapsWindow(start, end, existing) {
+		return nil, errors.New("window overlaps an existing window on this day")
+	}
+
 	avail := &domain.Availability{
 		ProviderID: providerID,
 		DayOfWeek:  input.DayOfWeek,
-		StartTime:  input.StartTime,
-		EndTime:    input.EndTime,
+		StartTime:  start,
+		EndTime:    end,
+	}
+	if err := s.availRepo.Save(avail); err != nil {
+		return nil, err
+	}
+
+	s.bus.Publish(event.AvailabilityChanged{ProviderID: providerID})
+	return avail, nil
+}
+
+func (s *AvailabilityService) DeleteAvailability(providerID, availabilityID uuid.UUID) error {
+	avail, err := s.availRepo.FindByID(availabilityID)
+	if err != nil {
+		return errors.New("availability not found")
+	}
+	if avail.ProviderID != providerID {
+		return errors.New("unauthorized to modify this availability")
+	}
+
+	if err := s.availRepo.Delete(availabilityID); err != nil {
+		return err
+	}
+
+	s.bus.Publish(event.AvailabilityChanged{ProviderID: providerID})
+	return nil
+}
+
+// AddOverride records a date-specific exception: extra hours, a partial closure or a day off
+func (s *AvailabilityService) AddOverride(providerID uuid.UUID, input AvailabilityOverrideInput) (*domain.AvailabilityOverride, error) {
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		return nil, errors.New("invalid date format (use YYYY-MM-DD)")
+	}
+
+	overrideType := domain.OverrideType(input.Type)
+	hasTimes := input.StartTime != "" || input.EndTime != ""
+
+	var start, end string
+	switch overrideType {
+	case domain.OverrideOpen:
+		if start, end, err = normalizeClockRange(input.StartTime, input.EndTime); err != nil {
+			return nil, err
+		}
+	case domain.OverrideClosed:
+		if hasTimes {
+			if start, end, err = normalizeClockRange(input.StartTime, input.EndTime); err != nil {
+				return nil, err
+			}
+		}
+	case domain.OverrideHoliday:
+		if hasTimes {
+			return nil, errors.New("holidays cover the whole day and cannot have times")
+		}
 	}
-	return s.availRepo.Save(avail)
+
+	override := &domain.AvailabilityOverride{
+		ProviderID: providerID,
+		Date:       date,
+		Type:       overrideType,
+		StartTime:  start,
+		EndTime:    end,
+		Reason:     input.Reason,
+	}
+	if err := s.availRepo.SaveOverride(override); err != nil {
+		return nil, err
+	}
+
+	s.bus.Publish(event.AvailabilityChanged{ProviderID: providerID, Date: input.Date})
+	return override, nil
 }
 
-// Calculates free time slots
-func (s *AvailabilityService) GetAvailableSlots(providerID uuid.UUID, dateStr string) ([]time.Time, error) {
+func (s *AvailabilityService) DeleteOverride(providerID, overrideID uuid.UUID) error {
+	override, err := s.availRepo.FindOverrideByID(overrideID)
+	if err != nil {
+		return errors.New("override not found")
+	}
+	if override.ProviderID != providerID {
+		return errors.New("unauthorized to modify this override")
+	}
 
+	if err := s.availRepo.DeleteOverride(overrideID); err != nil {
+		return err
+	}
+
+	s.bus.Publish(event.AvailabilityChanged{ProviderID: providerID, Date: override.Date.Format("2006-01-02")})
+	return nil
+}
+
+// GetSchedule returns the weekly windows plus upcoming date overrides
+func (s *AvailabilityService) GetSchedule(providerID uuid.UUID) (*Schedule, error) {
+	weekly, err := s.availRepo.GetByProvider(providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := s.providerLocation(providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	overrides, err := s.availRepo.GetOverridesByProvider(providerID, today)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schedule{TimeZone: loc.String(), Weekly: weekly, Overrides: overrides}, nil
+}
+
+// Calculates free time slots. dateStr is a calendar date in the provider's time zone.
+// When serviceID is set, slots are sized by the service duration and its buffers;
+// otherwise a 30 minute default is used. Slots are rendered in displayTZ, or in the
+// provider's zone when it is empty.
+func (s *AvailabilityService) GetAvailableSlots(providerID uuid.UUID, dateStr string, serviceID *uuid.UUID, displayTZ string) ([]time.Time, error) {
+	var displayLoc *time.Location
+	if displayTZ != "" {
+		loc, err := loadLocation(displayTZ)
+		if err != nil {
+			return nil, err
+		}
+		displayLoc = loc
+	}
+
+	providerLoc, err := s.providerLocation(providerID)
+	if err != nil {
+		return nil, err
+	}
+	if displayLoc == nil {
+		displayLoc = providerLoc
+	}
+
+	size, err := s.resolveSlotSize(providerID, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := s.computeSlots(providerID, dateStr, serviceID, size, providerLoc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Holds expire on their own, so they are applied after the cache, never stored in it
+	slots = s.withoutHeldSlots(providerID, slots, size)
+
+	for i := range slots {
+		slots[i] = slots[i].In(displayLoc)
+	}
+	return slots, nil
+}
+
+// slotSize is the appointment length plus the buffers it blocks around itself
+type slotSize struct {
+	Duration     time.Duration
+	BufferBefore time.Duration
+	BufferAfter  time.Duration
+}
+
+func (z slotSize) blockLength() time.Duration {
+	return z.BufferBefore + z.Duration + z.BufferAfter
+}
+
+// resolveSlotSize uses the service's sizing, or the default when serviceID is nil
+func (s *AvailabilityService) resolveSlotSize(providerID uuid.UUID, serviceID *uuid.UUID) (slotSize, error) {
+	if serviceID == nil {
+		return slotSize{Duration: defaultSlotDuration}, nil
+	}
+
+	svc, err := s.serviceRepo.FindByID(*serviceID)
+	if err != nil {
+		return slotSize{}, errors.New("service not found")
+	}
+	offered, err := s.serviceRepo.IsOfferedBy(svc.ID, providerID)
+	if err != nil {
+		return slotSize{}, err
+	}
+	if !offered {
+		return slotSize{}, errors.New("provider does not offer this service")
+	}
+	return slotSize{Duration: svc.Duration(), BufferBefore: svc.BufferBefore(), BufferAfter: svc.BufferAfter()}, nil
+}
+
+// withoutHeldSlots drops slots whose blocked range overlaps a live hold.
+// If Redis can't be read, holds are ignored rather than failing the request.
+func (s *AvailabilityService) withoutHeldSlots(providerID uuid.UUID, slots []time.Time, size slotSize) []time.Time {
+	holds, err := s.holdRepo.ListActive(context.Background(), providerID)
+	if err != nil {
+		log.Printf("Hold lookup failed for provider %s: %v", providerID, err)
+		return slots
+	}
+	if len(holds) == 0 {
+		return slots
+	}
+
+	free := make([]time.Time, 0, len(slots))
+	for _, slot := range slots {
+		blockStart := slot.Add(-size.BufferBefore)
+		blockEnd := blockStart.Add(size.blockLength())
+
+		held := false
+		for _, h := range holds {
+			if blockStart.Before(h.BlockEnd) && blockEnd.After(h.BlockStart) {
+				held = true
+				break
+			}
+		}
+		if !held {
+			free = append(free, slot)
+		}
+	}
+	return free
+}
+
+// computeSlots returns free slot start instants, cached in Redis per provider/date/service
+func (s *AvailabilityService) computeSlots(providerID uuid.UUID, dateStr string, serviceID *uuid.UUID, size slotSize, loc *time.Location) ([]time.Time, error) {
 	ctx := context.Background()
 
 	// 1. Define Cache Key (e.g., "slots:uuid:2025-10-30")
-	cacheKey := fmt.Sprintf("slots:%s:%s", providerID.String(), dateStr)
+	cacheKey := slotCacheKey(providerID, dateStr, serviceID)
 
 	// 2. Try Fetching from Redis
 	val, err := s.redis.Get(ctx, cacheKey).Result()
@@ -67,48 +312,51 @@ func (s *AvailabilityService) GetAvailableSlots(providerID uuid.UUID, dateStr st
 		return nil, errors.New("invalid date format (use YYYY-MM-DD)")
 	}
 
-	// 2. Get Working Hours
-	dayOfWeek := int(date.Weekday())
-	avail, err := s.availRepo.GetByProviderAndDay(providerID, dayOfWeek)
+	blockLength := size.blockLength()
+
+	// 2. Get Working Hours (weekly windows merged with date overrides)
+	windows, err := s.workingWindows(providerID, date, loc)
 	if err != nil {
+		return nil, err
+	}
+	if len(windows) == 0 {
 		return nil, errors.New("provider not available on this day")
 	}
 
-	// 3. Get Existing Appointments
-	appointments, err := s.apptRepo.GetProviderAppointments(providerID, date)
+	// 3. Get Existing Appointments (local midnight to midnight; not always 24h around DST)
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	endOfDay := time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, loc)
+	appointments, err := s.apptRepo.GetProviderAppointments(providerID, startOfDay, endOfDay)
 	if err != nil {
 		return nil, err
 	}
 
 	// 4. Algorithm: Generate Slots
+	// Each candidate blocks [blockStart, blockStart+blockLength), buffers included,
+	// and must fit entirely inside a working window.
 	var slots []time.Time
 
-	// Parse "09:00" into actual time for that specific date
-	startHour, _ := time.Parse("15:04", avail.StartTime)
-	endHour, _ := time.Parse("15:04", avail.EndTime)
-
-	current := time.Date(date.Year(), date.Month(), date.Day(), startHour.Hour(), startHour.Minute(), 0, 0, time.UTC)
-	end := time.Date(date.Year(), date.Month(), date.Day(), endHour.Hour(), endHour.Minute(), 0, 0, time.UTC)
+	for _, window := range windows {
+		blockStart := window.Start
 
-	slotDuration := 30 * time.Minute // Hardcoded for now, could be dynamic
+		for !blockStart.Add(blockLength).After(window.End) {
+			blockEnd := blockStart.Add(blockLength)
 
-	for current.Add(slotDuration).Before(end) || current.Add(slotDuration).Equal(end) {
-		slotEnd := current.Add(slotDuration)
+			isBooked := false
+			for _, appt := range appointments {
+				// Check overlap: (StartA < EndB) && (EndA > StartB)
+				if blockStart.Before(appt.BlockEnd) && blockEnd.After(appt.BlockStart) {
+					isBooked = true
+					break
+				}
+			}
 
-		isBooked := false
-		for _, appt := range appointments {
-			// Check overlap: (StartA < EndB) && (EndA > StartB)
-			if current.Before(appt.EndTime) && slotEnd.After(appt.StartTime) {
-				isBooked = true
-				break
+			if !isBooked {
+				slots = append(slots, blockStart.Add(size.BufferBefore))
 			}
-		}
 
-		if !isBooked {
-			slots = append(slots, current)
+			blockStart = blockEnd
 		}
-
-		current = current.Add(slotDuration)
 	}
 
 	data, _ := json.Marshal(slots)
@@ -116,3 +364,72 @@ func (s *AvailabilityService) GetAvailableSlots(providerID uuid.UUID, dateStr st
 
 	return slots, nil
 }
+
+// workingWindows resolves the provider's open intervals for one date:
+// weekly windows, plus OPEN overrides, minus CLOSED/HOLIDAY overrides.
+func (s *AvailabilityService) workingWindows(providerID uuid.UUID, date time.Time, loc *time.Location) ([]timeWindow, error) {
+	weekly, err := s.availRepo.GetByProviderAndDay(providerID, int(date.Weekday()))
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := s.availRepo.GetOverridesByProviderAndDate(providerID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	closedAllDay := false
+	for _, o := range overrides {
+		if o.IsFullDay() {
+			closedAllDay = true
+			break
+		}
+	}
+
+	var windows []timeWindow
+	if !closedAllDay {
+		for _, a := range weekly {
+			w, err := windowOnDate(date, a.StartTime, a.EndTime, loc)
+			if err != nil {
+				return nil, err
+			}
+			windows = append(windows, w)
+		}
+	}
+
+	// Explicit extra hours still apply on an otherwise closed day
+	for _, o := range overrides {
+		if o.Type != domain.OverrideOpen {
+			continue
+		}
+		w, err := windowOnDate(date, o.StartTime, o.EndTime, loc)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+
+	windows = mergeWindows(windows)
+
+	for _, o := range overrides {
+		if o.Type != domain.OverrideClosed || o.IsFullDay() {
+			continue
+		}
+		blocked, err := windowOnDate(date, o.StartTime, o.EndTime, loc)
+		if err != nil {
+			return nil, err
+		}
+		windows = subtractWindow(windows, blocked)
+	}
+
+	return windows, nil
+}
+
+// providerLocation loads the provider's configured time zone
+func (s *AvailabilityService) providerLocation(providerID uuid.UUID) (*time.Location, error) {
+	provider, err := s.userRepo.FindByID(providerID)
+	if err != nil || !provider.Roles.Has(domain.RoleProvider) {
+		return nil, errors.New("provider not found")
+	}
+	return loadLocation(provider.TimeZone)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/repository"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrPolicyViolation is returned when a cancellation breaks the provider's policy
+var ErrPolicyViolation = errors.New("cancellation policy violation")
+
+type CancellationPolicyService struct {
+	repo *repository.CancellationPolicyRepository
+}
+
+func NewCancellationPolicyService(repo *repository.CancellationPolicyRepository) *CancellationPolicyService {
+	return &CancellationPolicyService{repo: repo}
+}
+
+type CancellationRuleInput struct {
+	MinNoticeMinutes int  `json:"min_notice_minutes" binding:"min=0"`
+	AllowLate        bool `json:"allow_late"`
+	RequireReason    bool `json:"require_reason"`
+}
+
+type CancellationPolicyInput struct {
+	Customer CancellationRuleInput `json:"customer"`
+	Provider CancellationRuleInput `json:"provider"`
+}
+
+// CancelInput is the body of a cancellation request
+type CancelInput struct {
+	ReasonCode string `json:"reason_code"` // One of the domain.CancellationReason values
+	Reason     string `json:"reason"`      // Free-text note
+}
+
+func (s *CancellationPolicyService) GetPolicy(providerID uuid.UUID) (*domain.CancellationPolicy, error) {
+	return s.repo.FindByProvider(nil, providerID)
+}
+
+func (s *CancellationPolicyService) SetPolicy(actor domain.Actor, input CancellationPolicyInput) (*domain.CancellationPolicy, error) {
+	if !actor.Can(domain.PermManageSchedule) {
+		return nil, errors.New("only providers can set a cancellation policy")
+	}
+
+	policy := &domain.CancellationPolicy{
+		ProviderID: actor.ID,
+		Customer:   domain.CancellationRule(input.Customer),
+		Provider:   domain.CancellationRule(input.Provider),
+	}
+	if err := s.repo.Upsert(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// evaluateCancellation checks a cancellation against the rule for the cancelling side
+// and reports whether it is late (inside the minimum notice window).
+func evaluateCancellation(rule domain.CancellationRule, appt *domain.Appointment, input CancelInput, now time.Time) (bool, error) {
+	if input.ReasonCode != "" && !domain.CancellationReason(input.ReasonCode).IsValid() {
+		return false, fmt.Errorf("invalid reason code: %s", input.ReasonCode)
+	}
+	if rule.RequireReason && input.ReasonCode == "" {
+		return false, fmt.Errorf("%w: a reason code is required", ErrPolicyViolation)
+	}
+
+	late := appt.StartTime.Sub(now) < rule.MinNotice()
+	if late && !rule.AllowLate {
+		return false, fmt.Errorf("%w: cancellations need at least %d minutes notice", ErrPolicyViolation, rule.MinNoticeMinutes)
+	}
+	return late, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/repository"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// CatalogService manages the bookable services and which providers offer them
+type CatalogService struct {
+	repo     *repository.ServiceRepository
+	userRepo *repository.UserRepository
+}
+
+func NewCatalogService(repo *repository.ServiceRepository, userRepo *repository.UserRepository) *CatalogService {
+	return &CatalogService{repo: repo, userRepo: userRepo}
+}
+
+type ServiceInput struct {
+	Name                string   `json:"name" binding:"required,max=100"`
+	Description         string   `json:"description"`
+	DurationMinutes     int      `json:"duration_minutes" binding:"required,min=5"`
+	BufferBeforeMinutes int      `json:"buffer_before_minutes" binding:"min=0"`
+	BufferAfterMinutes  int      `json:"buffer_after_minutes" binding:"min=0"`
+	PriceCents          int64    `json:"price_cents" binding:"min=0"`
+	Currency            string   `json:"currency" binding:"omitempty,len=3"`
+	ProviderIDs         []string `json:"provider_ids"`
+}
+
+func (s *CatalogService) CreateService(input ServiceInput) (*domain.Service, error) {
+	providers, err := s.resolveProviders(input.ProviderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &domain.Service{
+		Providers: providers,
+	}
+	applyServiceInput(svc, input)
+
+	if err := s.repo.Create(svc); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+func (s *CatalogService) UpdateService(id uuid.UUID, input ServiceInput) (*domain.Service, error) {
+	svc, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("service not found")
+	}
+
+	providers, err := s.resolveProviders(input.ProviderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	applyServiceInput(svc, input)
+	if err := s.repo.Update(svc); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceProviders(svc, providers); err != nil {
+		return nil, err
+	}
+
+	svc.Providers = providers
+	return svc, nil
+}
+
+func (s *CatalogService) DeleteService(id uuid.UUID) error {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return errors.New("service not found")
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *CatalogService) GetService(id uuid.UUID) (*domain.Service, error) {
+	svc, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("service not found")
+	}
+	return svc, nil
+}
+
+// ListServices returns the catalog, optionally filtered to one provider's offerings
+func (s *CatalogService) ListServices(providerID *uuid.UUID) ([]domain.Service, error) {
+	return s.repo.List(providerID)
+}
+
+func applyServiceInput(svc *domain.Service, input ServiceInput) {
+	svc.Name = input.Name
+	svc.Description = input.Description
+	svc.DurationMinutes = input.DurationMinutes
+	svc.BufferBeforeMinutes = input.BufferBeforeMinutes
+	svc.BufferAfterMinutes = input.BufferAfterMinutes
+	svc.PriceCents = input.PriceCents
+	svc.Currency = input.Currency
+	if svc.Currency == "" {
+		svc.Currency = "USD"
+	}
+}
+
+// resolveProviders parses the IDs and makes sure each one is an existing provider
+func (s *CatalogService) resolveProviders(rawIDs []string) ([]domain.User, error) {
+	if len(rawIDs) == 0 {
+		return []domain.User{}, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(rawIDs))
+	seen := make(map[uuid.UUID]bool)
+	for _, raw := range rawIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, errors.New("invalid provider ID")
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	providers, err := s.userRepo.FindProvidersByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(providers) != len(ids) {
+		return nil, errors.New("one or more provider IDs do not belong to a provider")
+	}
+	return providers, nil
+}
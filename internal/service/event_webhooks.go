@@ -0,0 +1,109 @@
+package service
+
+import (
+	"appointment-booking/internal/event"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// How long one webhook delivery may take
+const eventWebhookTimeout = 10 * time.Second
+
+// EventWebhooks forwards domain events to external endpoints configured by the operator.
+// Delivery is best effort: each POST runs in the background and failures are only logged.
+type EventWebhooks struct {
+	urls   []string
+	client *http.Client
+}
+
+func NewEventWebhooks(urls []string) *EventWebhooks {
+	return &EventWebhooks{urls: urls, client: &http.Client{Timeout: eventWebhookTimeout}}
+}
+
+type eventWebhookPayload struct {
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Subscribe does nothing when no URLs are configured
+func (w *EventWebhooks) Subscribe(bus *event.Bus) {
+	if len(w.urls) == 0 {
+		return
+	}
+
+	event.On(bus, func(e event.AppointmentBooked) {
+		w.send(e, appointmentEventData(e.AppointmentEvent))
+	})
+	event.On(bus, func(e event.AppointmentCancelled) {
+		data := appointmentEventData(e.AppointmentEvent)
+		data["note"] = e.Note
+		w.send(e, data)
+	})
+	event.On(bus, func(e event.AppointmentRescheduled) {
+		data := appointmentEventData(e.AppointmentEvent)
+		data["old_start_time"] = e.OldStartTime
+		w.send(e, data)
+	})
+	event.On(bus, func(e event.AppointmentStatusChanged) {
+		w.send(e, appointmentEventData(e.AppointmentEvent))
+	})
+	event.On(bus, func(e event.AvailabilityChanged) {
+		w.send(e, map[string]interface{}{"provider_id": e.ProviderID, "date": e.Date})
+	})
+}
+
+func appointmentEventData(e event.AppointmentEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"appointment": e.Appointment,
+		"actor_id":    e.Actor.ID,
+		"actor_roles": e.Actor.Roles,
+		"old_status":  e.OldStatus,
+		"reason":      e.Reason,
+	}
+}
+
+// send encodes the event now, while the appointment can't change underneath it, and
+// posts it to every URL in the background
+func (w *EventWebhooks) send(e event.Event, data interface{}) {
+	payload, err := json.Marshal(eventWebhookPayload{Event: e.Name(), OccurredAt: time.Now(), Data: data})
+	if err != nil {
+		log.Printf("Event webhook: encoding %s: %v", e.Name(), err)
+		return
+	}
+
+	for _, url := range w.urls {
+		go func() {
+			if err := w.post(url, payload); err != nil {
+				log.Printf("Event webhook: %s to %s: %v", e.Name(), url, err)
+			}
+		}()
+	}
+}
+
+func (w *EventWebhooks) post(url string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), eventWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrHoldLimit is returned when the customer already holds as many slots as allowed
+var ErrHoldLimit = repository.ErrHoldLimit
+
+// HoldService reserves slots for a short time while a customer completes checkout.
+// Each customer may hold at most maxPerCustomer slots at once, so one account can't
+// lock up a provider's calendar.
+type HoldService struct {
+	holdRepo       *repository.HoldRepository
+	apptRepo       *repository.AppointmentRepository
+	apptService    *AppointmentService
+	ttl            time.Duration
+	maxPerCustomer int
+}
+
+func NewHoldService(holdRepo *repository.HoldRepository, apptRepo *repository.AppointmentRepository, apptService *AppointmentService, ttl time.Duration, maxPerCustomer int) *HoldService {
+	return &HoldService{
+		holdRepo:       holdRepo,
+		apptRepo:       apptRepo,
+		apptService:    apptService,
+		ttl:            ttl,
+		maxPerCustomer: maxPerCustomer,
+	}
+}
+
+// CreateHold reserves the slot described by input for the configured TTL, on behalf of
+// the actor, who must be allowed to book
+func (s *HoldService) CreateHold(actor domain.Actor, input BookingInput) (*domain.SlotHold, error) {
+	if !actor.Can(domain.PermBookAppointments) {
+		return nil, ErrForbidden
+	}
+	customerID := actor.ID
+
+	// 1. Validate & size exactly like a booking
+	appt, err := s.apptService.prepareBooking(customerID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Already booked? The provider lock is the one bookings take before checking
+	// holds, so a booking either committed before our check or sees our hold.
+	tx := s.apptRepo.BeginTx()
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := s.apptRepo.LockProvider(tx, appt.ProviderID); err != nil {
+		tx.Rollback()
+		return nil, errors.New("provider not found")
+	}
+
+	hasOverlap, err := s.apptRepo.HasOverlap(tx, appt.ProviderID, appt.BlockStart, appt.BlockEnd, nil)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if hasOverlap {
+		tx.Rollback()
+		return nil, ErrSlotTaken
+	}
+
+	// 3. Reserve (atomic against other holds and the customer's limit)
+	hold := &domain.SlotHold{
+		ID:         uuid.New(),
+		CustomerID: customerID,
+		ProviderID: appt.ProviderID,
+		ServiceID:  *appt.ServiceID,
+		StartTime:  appt.StartTime,
+		EndTime:    appt.EndTime,
+		BlockStart: appt.BlockStart,
+		BlockEnd:   appt.BlockEnd,
+		ExpiresAt:  time.Now().Add(s.ttl),
+	}
+	if err := s.holdRepo.Create(context.Background(), hold, s.maxPerCustomer); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Nothing was written to the database; ending the transaction releases the lock
+	if err := tx.Commit().Error; err != nil {
+		_ = s.holdRepo.Delete(context.Background(), hold)
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// ConfirmHold turns the customer's hold into a real appointment and releases it
+func (s *HoldService) ConfirmHold(actor domain.Actor, holdID uuid.UUID) (*domain.Appointment, error) {
+	hold, err := s.findOwnHold(actor.ID, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	appointment, err := s.apptService.BookAppointment(actor, BookingInput{
+		ProviderID: hold.ProviderID.String(),
+		ServiceID:  hold.ServiceID.String(),
+		StartTime:  hold.StartTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The appointment now blocks the slot; a leftover hold would expire anyway
+	_ = s.holdRepo.Delete(context.Background(), hold)
+
+	return appointment, nil
+}
+
+// ReleaseHold gives the slot back before the hold expires
+func (s *HoldService) ReleaseHold(customerID, holdID uuid.UUID) error {
+	hold, err := s.findOwnHold(customerID, holdID)
+	if err != nil {
+		return err
+	}
+	return s.holdRepo.Delete(context.Background(), hold)
+}
+
+func (s *HoldService) findOwnHold(customerID, holdID uuid.UUID) (*domain.SlotHold, error) {
+	hold, err := s.holdRepo.FindByID(context.Background(), holdID)
+	if err == redis.Nil {
+		return nil, errors.New("hold not found or expired")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hold.CustomerID != customerID {
+		return nil, errors.New("unauthorized to use this hold")
+	}
+	return hold, nil
+}
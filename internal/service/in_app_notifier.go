@@ -0,0 +1,35 @@
+package service
+
+import (
+	"appointment-booking/internal/websocket"
+	"appointment-booking/pkg/notifier"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// inAppNotifier pushes notifications to the user's open WebSocket connections.
+// Message.To holds the user ID.
+type inAppNotifier struct {
+	ws *websocket.Handler
+}
+
+func NewInAppNotifier(ws *websocket.Handler) notifier.Notifier {
+	return &inAppNotifier{ws: ws}
+}
+
+func (n *inAppNotifier) Send(ctx context.Context, msg notifier.Message) error {
+	userID, err := uuid.Parse(msg.To)
+	if err != nil {
+		return err
+	}
+
+	n.ws.SendToUsers(map[string]interface{}{
+		"event":   "notification",
+		"user_id": msg.To,
+		"type":    msg.Event,
+		"subject": msg.Subject,
+		"body":    msg.Body,
+	}, userID)
+	return nil
+}
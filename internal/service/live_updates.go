@@ -0,0 +1,39 @@
+package service
+
+import (
+	"appointment-booking/internal/event"
+	"appointment-booking/internal/websocket"
+)
+
+// LiveUpdates pushes appointment changes and waitlist offers to the sockets of the
+// people involved and hangs up the sockets of revoked sessions
+type LiveUpdates struct {
+	ws *websocket.Handler
+}
+
+func NewLiveUpdates(ws *websocket.Handler) *LiveUpdates {
+	return &LiveUpdates{ws: ws}
+}
+
+func (l *LiveUpdates) Subscribe(bus *event.Bus) {
+	event.On(bus, func(e event.AppointmentBooked) {
+		l.ws.Publish(map[string]interface{}{
+			"event":       "new_booking",
+			"provider_id": e.Appointment.ProviderID,
+			"slot":        e.Appointment.StartTime,
+		}, e.Appointment.CustomerID, e.Appointment.ProviderID)
+	})
+	event.On(bus, func(e event.WaitlistOfferMade) {
+		l.ws.Publish(map[string]interface{}{
+			"event":       "waitlist_offer",
+			"customer_id": e.CustomerID,
+			"entry_id":    e.EntryID,
+			"provider_id": e.ProviderID,
+			"slot":        e.Slot,
+			"expires_at":  e.ExpiresAt,
+		}, e.CustomerID)
+	})
+	event.On(bus, func(e event.SessionsRevoked) {
+		l.ws.CloseSessions(e.SessionIDs...)
+	})
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/pkg/notifier"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Only non-nil fields are updated
+type NotificationPreferencesInput struct {
+	// Event name -> channels ("email", "in_app", "webhook"); an empty list mutes the event
+	Events          map[string][]string `json:"events"`
+	WebhookURL      *string             `json:"webhook_url"`
+	QuietHoursStart *string             `json:"quiet_hours_start"` // "22:00", "" turns quiet hours off
+	QuietHoursEnd   *string             `json:"quiet_hours_end"`   // "07:00"
+}
+
+func (s *NotificationService) GetPreferences(userID uuid.UUID) (*domain.NotificationPreference, error) {
+	return s.prefRepo.FindByUser(nil, userID)
+}
+
+func (s *NotificationService) UpdatePreferences(userID uuid.UUID, input NotificationPreferencesInput) (*domain.NotificationPreference, error) {
+	pref, err := s.prefRepo.FindByUser(nil, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Events != nil {
+		if pref.Events == nil {
+			pref.Events = domain.EventChannels{}
+		}
+		for event, names := range input.Events {
+			// Account emails always go out by email, so they have no preference to set
+			if !NotificationEvent(event).IsValid() || NotificationEvent(event).IsAccount() {
+				return nil, errors.New("unknown notification event: " + event)
+			}
+			channels := make([]domain.NotificationChannel, 0, len(names))
+			for _, name := range names {
+				channel := domain.NotificationChannel(name)
+				if !channel.IsValid() {
+					return nil, errors.New("unknown notification channel: " + name)
+				}
+				channels = append(channels, channel)
+			}
+			pref.Events[event] = channels
+		}
+	}
+
+	if input.WebhookURL != nil {
+		// Checked again on every delivery, since the host may resolve differently later
+		if *input.WebhookURL != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			err := notifier.ValidateWebhookURL(ctx, *input.WebhookURL)
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("invalid webhook URL: %w", err)
+			}
+		}
+		pref.WebhookURL = *input.WebhookURL
+	}
+
+	if input.QuietHoursStart != nil {
+		pref.QuietHoursStart = *input.QuietHoursStart
+	}
+	if input.QuietHoursEnd != nil {
+		pref.QuietHoursEnd = *input.QuietHoursEnd
+	}
+	if err := validateQuietHours(pref.QuietHoursStart, pref.QuietHoursEnd); err != nil {
+		return nil, err
+	}
+
+	if err := s.prefRepo.Upsert(pref); err != nil {
+		return nil, err
+	}
+	return pref, nil
+}
+
+// validateQuietHours accepts both empty, or two different "HH:MM" values. The range may
+// wrap past midnight ("22:00"-"07:00").
+func validateQuietHours(start, end string) error {
+	if start == "" && end == "" {
+		return nil
+	}
+	s, err := parseClock(start)
+	if err != nil {
+		return errors.New("quiet hours need both a start and an end (HH:MM)")
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return errors.New("quiet hours need both a start and an end (HH:MM)")
+	}
+	if s.Equal(e) {
+		return errors.New("quiet hours start and end must differ")
+	}
+	return nil
+}
+
+// quietHoursEnd reports whether now falls in the user's quiet hours and, if so, when they end
+func quietHoursEnd(pref *domain.NotificationPreference, zone string, now time.Time) (time.Time, bool) {
+	if !pref.HasQuietHours() {
+		return time.Time{}, false
+	}
+	loc, err := loadLocation(zone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	today, err := windowOnDate(local, pref.QuietHoursStart, pref.QuietHoursEnd, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if today.Start.Before(today.End) {
+		// Same-day range, e.g. 13:00-15:00
+		if !local.Before(today.Start) && local.Before(today.End) {
+			return today.End, true
+		}
+		return time.Time{}, false
+	}
+
+	// Overnight range, e.g. 22:00-07:00
+	if local.Before(today.End) {
+		return today.End, true
+	}
+	if !local.Before(today.Start) {
+		tomorrow := local.AddDate(0, 0, 1)
+		end := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), today.End.Hour(), today.End.Minute(), 0, 0, loc)
+		return end, true
+	}
+	return time.Time{}, false
+}
@@ -1,43 +1,418 @@
 package service
 
 import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/event"
+	"appointment-booking/internal/repository"
+	"appointment-booking/pkg/notifier"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"log"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-type NotificationPayload struct {
-	UserID  uuid.UUID
-	Message string
+const (
+	// How long a single delivery may take before we give up on it
+	sendTimeout = 15 * time.Second
+
+	// How often the worker looks for due notifications
+	notificationPollInterval = 2 * time.Second
+	notificationBatchSize    = 50
+
+	// A claimed notification is invisible to other instances for this long
+	notificationLease = 5 * time.Minute
+
+	// Retry delays double from retryBaseDelay up to retryMaxDelay
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = time.Hour
+)
+
+const notificationTimeLayout = "Mon, 02 Jan 2006 15:04 MST"
+
+var ErrNotReplayable = errors.New("only dead notifications can be replayed")
+
+// redactedPayload replaces the payload of account emails in the admin listing
+var redactedPayload = json.RawMessage(`{"redacted":true}`)
+
+// NotificationData carries the event details; times are rendered in the recipient's zone
+type NotificationData struct {
+	Service      string    `json:"service,omitempty"`
+	StartTime    time.Time `json:"start_time,omitzero"`
+	OldStartTime time.Time `json:"old_start_time,omitzero"`
+	Reason       string    `json:"reason,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitzero"`
+	Link         string    `json:"link,omitempty"` // Single-use account link; redacted from List
 }
 
+// NotificationService writes notifications to the outbox and delivers them from a
+// background worker, retrying with exponential backoff until maxAttempts. Each user's
+// preferences decide which channels an event goes out on and when.
 type NotificationService struct {
-	// Buffered channel to hold messages
-	notifyChan chan NotificationPayload
+	repo        *repository.NotificationRepository
+	prefRepo    *repository.NotificationPreferenceRepository
+	userRepo    *repository.UserRepository
+	senders     map[domain.NotificationChannel]notifier.Notifier
+	templates   map[string]map[NotificationEvent]compiledTemplate
+	maxAttempts int
+	wg          sync.WaitGroup
 }
 
-func NewNotificationService() *NotificationService {
+func NewNotificationService(repo *repository.NotificationRepository, prefRepo *repository.NotificationPreferenceRepository, userRepo *repository.UserRepository, senders map[domain.NotificationChannel]notifier.Notifier, maxAttempts int) *NotificationService {
 	return &NotificationService{
-		// Buffer of 100: prevents blocking if bursts of requests come in
-		notifyChan: make(chan NotificationPayload, 100),
+		repo:        repo,
+		prefRepo:    prefRepo,
+		userRepo:    userRepo,
+		senders:     senders,
+		templates:   compileTemplates(),
+		maxAttempts: maxAttempts,
 	}
 }
 
-// SendAsync pushes the message to the queue and returns immediately
-func (s *NotificationService) SendAsync(userID uuid.UUID, message string) {
-	s.notifyChan <- NotificationPayload{UserID: userID, Message: message}
+// Subscribe queues notifications to both parties of an appointment change, in the
+// transaction of the change
+func (s *NotificationService) Subscribe(bus *event.Bus) {
+	event.OnTx(bus, func(tx *gorm.DB, e event.AppointmentBooked) error {
+		return s.notifyParties(tx, e.Appointment, EventBooked, NotificationData{})
+	})
+	event.OnTx(bus, func(tx *gorm.DB, e event.AppointmentCancelled) error {
+		return s.notifyParties(tx, e.Appointment, EventCancelled, NotificationData{Reason: e.Note})
+	})
+	event.OnTx(bus, func(tx *gorm.DB, e event.AppointmentRescheduled) error {
+		return s.notifyParties(tx, e.Appointment, EventRescheduled, NotificationData{OldStartTime: e.OldStartTime})
+	})
+}
+
+// notifyParties queues the event for both the customer and the provider of appt.
+// data.Service and data.StartTime are filled in from the appointment.
+func (s *NotificationService) notifyParties(tx *gorm.DB, appt *domain.Appointment, event NotificationEvent, data NotificationData) error {
+	data.Service = appt.ServiceType
+	data.StartTime = appt.StartTime
+	if err := s.Enqueue(tx, appt.CustomerID, event, data); err != nil {
+		return err
+	}
+	return s.Enqueue(tx, appt.ProviderID, event, data)
+}
+
+// Enqueue adds the notification to the outbox, once per channel the user wants the event
+// on. Pass the caller's tx so the notification only exists if the change it announces commits.
+func (s *NotificationService) Enqueue(tx *gorm.DB, userID uuid.UUID, event NotificationEvent, data NotificationData) error {
+	pref, err := s.prefRepo.FindByUser(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	channels := pref.ChannelsFor(string(event))
+	if event.IsAccount() {
+		channels = []domain.NotificationChannel{domain.ChannelEmail}
+	}
+
+	now := time.Now()
+	for _, channel := range channels {
+		if channel == domain.ChannelWebhook && pref.WebhookURL == "" {
+			continue
+		}
+		err := s.repo.Create(tx, &domain.Notification{
+			UserID:        userID,
+			Event:         string(event),
+			Channel:       channel,
+			Payload:       payload,
+			Status:        domain.NotificationPending,
+			NextAttemptAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// StartWorker is the background process that actually sends the emails
-func (s *NotificationService) StartWorker() {
+// StartWorker delivers due notifications until ctx is cancelled. Use Wait to block
+// until the batch in flight has finished.
+func (s *NotificationService) StartWorker(ctx context.Context) {
+	s.wg.Add(1)
 	go func() {
-		for payload := range s.notifyChan {
-			// Simulate slow email server (e.g., SMTP latency)
-			time.Sleep(2 * time.Second)
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(notificationPollInterval)
+		defer ticker.Stop()
 
-			// In a real app, you would call SendGrid/AWS SES here
-			log.Printf("📧 [Email Sent] To User: %s | Body: %s", payload.UserID, payload.Message)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.DeliverDue(ctx)
+			}
 		}
 	}()
 }
+
+// Wait blocks until the worker has stopped, or ctx expires
+func (s *NotificationService) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DeliverDue sends due notifications batch by batch until none are left or ctx is cancelled
+func (s *NotificationService) DeliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		notifications, err := s.repo.ClaimDue(time.Now(), notificationLease, notificationBatchSize)
+		if err != nil {
+			log.Printf("Notification lookup failed: %v", err)
+			return
+		}
+
+		for i := range notifications {
+			s.deliver(&notifications[i])
+		}
+
+		if len(notifications) < notificationBatchSize {
+			return
+		}
+	}
+}
+
+func (s *NotificationService) deliver(n *domain.Notification) {
+	user, err := s.userRepo.FindByID(n.UserID)
+	if err != nil {
+		s.markDead(n, errors.New("recipient not found"))
+		return
+	}
+
+	pref, err := s.prefRepo.FindByUser(nil, n.UserID)
+	if err != nil {
+		s.retry(n, err)
+		return
+	}
+
+	// Preferences may have changed since the notification was queued
+	wanted := NotificationEvent(n.Event).IsAccount() || slices.Contains(pref.ChannelsFor(n.Event), n.Channel)
+	if !wanted || (n.Channel == domain.ChannelWebhook && pref.WebhookURL == "") {
+		if err := s.repo.MarkSkipped(n.ID); err != nil {
+			log.Printf("Failed to skip notification %s: %v", n.ID, err)
+		}
+		return
+	}
+
+	if !NotificationEvent(n.Event).IsUrgent() {
+		if until, quiet := quietHoursEnd(pref, user.TimeZone, time.Now()); quiet {
+			if err := s.repo.Defer(n.ID, until); err != nil {
+				log.Printf("Failed to defer notification %s: %v", n.ID, err)
+			}
+			return
+		}
+	}
+
+	sender, ok := s.senders[n.Channel]
+	if !ok {
+		s.markDead(n, errors.New("no sender for channel "+string(n.Channel)))
+		return
+	}
+
+	msg, err := s.compose(n, user, pref)
+	if err != nil {
+		// Retrying won't fix a bad payload
+		s.markDead(n, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	if err := sender.Send(ctx, msg); err != nil {
+		s.retry(n, err)
+		return
+	}
+
+	if err := s.repo.MarkSent(n.ID, time.Now()); err != nil {
+		log.Printf("Failed to mark notification %s sent: %v", n.ID, err)
+	}
+}
+
+// retry schedules the next attempt with backoff, or dead-letters n once it is out of attempts
+func (s *NotificationService) retry(n *domain.Notification, err error) {
+	if n.Attempts >= s.maxAttempts {
+		s.markDead(n, err)
+		return
+	}
+	next := time.Now().Add(retryDelay(n.Attempts))
+	log.Printf("Notification %s attempt %d failed, retrying at %s: %v", n.ID, n.Attempts, next.Format(time.RFC3339), err)
+	if err := s.repo.MarkFailed(n.ID, next, err.Error()); err != nil {
+		log.Printf("Failed to reschedule notification %s: %v", n.ID, err)
+	}
+}
+
+func (s *NotificationService) markDead(n *domain.Notification, cause error) {
+	log.Printf("Notification %s dead-lettered after %d attempt(s): %v", n.ID, n.Attempts, cause)
+	if err := s.repo.MarkDead(n.ID, cause.Error()); err != nil {
+		log.Printf("Failed to dead-letter notification %s: %v", n.ID, err)
+	}
+}
+
+// retryDelay is the wait after the given (1-based) failed attempt
+func retryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempt && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// compose renders the outbox row into a message addressed on its channel
+func (s *NotificationService) compose(n *domain.Notification, user *domain.User, pref *domain.NotificationPreference) (notifier.Message, error) {
+	var data NotificationData
+	if err := json.Unmarshal(n.Payload, &data); err != nil {
+		return notifier.Message{}, err
+	}
+
+	subject, body, err := s.render(user.Locale, user.TimeZone, user.Name, NotificationEvent(n.Event), data)
+	if err != nil {
+		return notifier.Message{}, err
+	}
+
+	msg := notifier.Message{
+		ID:        n.ID.String(),
+		Event:     n.Event,
+		Subject:   subject,
+		Body:      body,
+		Sensitive: NotificationEvent(n.Event).IsAccount(),
+	}
+	switch n.Channel {
+	case domain.ChannelEmail:
+		msg.To = user.Email
+	case domain.ChannelInApp:
+		msg.To = user.ID.String()
+	case domain.ChannelWebhook:
+		if pref.WebhookURL == "" {
+			return notifier.Message{}, errors.New("no webhook URL configured")
+		}
+		msg.To = pref.WebhookURL
+	}
+	return msg, nil
+}
+
+// render picks the template for the user's locale (falling back to English) and fills it in
+func (s *NotificationService) render(locale, zone, name string, event NotificationEvent, payload NotificationData) (string, string, error) {
+	events, ok := s.templates[locale]
+	if !ok {
+		events = s.templates[defaultLocale]
+	}
+	tmpl, ok := events[event]
+	if !ok {
+		tmpl, ok = s.templates[defaultLocale][event]
+		if !ok {
+			return "", "", errors.New("no template for event " + string(event))
+		}
+	}
+
+	loc, err := loadLocation(zone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	data := templateData{
+		Name:      name,
+		Service:   payload.Service,
+		Start:     formatNotificationTime(payload.StartTime, loc),
+		OldStart:  formatNotificationTime(payload.OldStartTime, loc),
+		Reason:    payload.Reason,
+		ExpiresAt: formatNotificationTime(payload.ExpiresAt, loc),
+		Link:      payload.Link,
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
+
+func formatNotificationTime(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(loc).Format(notificationTimeLayout)
+}
+
+type NotificationQuery struct {
+	Status string `form:"status"` // PENDING, SENT or DEAD
+	UserID string `form:"user_id"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// List lets admins inspect the outbox, e.g. ?status=DEAD for failed deliveries
+func (s *NotificationService) List(query NotificationQuery) ([]domain.Notification, error) {
+	filter := repository.NotificationFilter{
+		Status: domain.NotificationStatus(query.Status),
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 50
+	}
+
+	if query.UserID != "" {
+		id, err := uuid.Parse(query.UserID)
+		if err != nil {
+			return nil, errors.New("invalid user ID")
+		}
+		filter.UserID = &id
+	}
+
+	notifications, err := s.repo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Account emails carry a live reset or verification link; operators don't get to see it
+	for i := range notifications {
+		if NotificationEvent(notifications[i].Event).IsAccount() {
+			notifications[i].Payload = redactedPayload
+		}
+	}
+	return notifications, nil
+}
+
+// Replay queues a dead notification for delivery again
+func (s *NotificationService) Replay(id uuid.UUID) (*domain.Notification, error) {
+	ok, err := s.repo.Replay(id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if _, err := s.repo.FindByID(id); err != nil {
+			return nil, errors.New("notification not found")
+		}
+		return nil, ErrNotReplayable
+	}
+	return s.repo.FindByID(id)
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/pkg/notifier"
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// smtpSession is what the fake server saw during one conversation
+type smtpSession struct {
+	from string
+	rcpt []string
+	data string
+}
+
+// fakeSMTPServer accepts a single plain SMTP conversation (no STARTTLS, no AUTH), the
+// way MailHog does locally, and reports what it received
+func fakeSMTPServer(t *testing.T) (host string, port int, session <-chan smtpSession) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	done := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		text := textproto.NewConn(conn)
+		var s smtpSession
+		reply := func(line string) { _ = text.PrintfLine("%s", line) }
+
+		reply("220 localhost fake SMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch {
+			case verb == "EHLO" || verb == "HELO":
+				reply("250-localhost")
+				reply("250 8BITMIME")
+			case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+				s.from = envelopeAddress(line[len("MAIL FROM:"):])
+				reply("250 OK")
+			case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+				s.rcpt = append(s.rcpt, envelopeAddress(line[len("RCPT TO:"):]))
+				reply("250 OK")
+			case verb == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				b, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				s.data = string(b)
+				reply("250 OK")
+			case verb == "QUIT":
+				reply("221 Bye")
+				done <- s
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, done
+}
+
+// envelopeAddress extracts the address from "<addr> [params]"
+func envelopeAddress(arg string) string {
+	start, end := strings.IndexByte(arg, '<'), strings.IndexByte(arg, '>')
+	if start < 0 || end < start {
+		return strings.TrimSpace(arg)
+	}
+	return arg[start+1 : end]
+}
+
+func TestSMTPNotifierDeliversRenderedTemplate(t *testing.T) {
+	host, port, sessions := fakeSMTPServer(t)
+
+	sender := notifier.NewSMTPNotifier(notifier.SMTPConfig{
+		Host: host,
+		Port: port,
+		From: "no-reply@appointment-booking.local",
+	})
+
+	start := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	payload, _ := json.Marshal(NotificationData{Service: "Depilación", StartTime: start})
+	user := &domain.User{
+		ID:       uuid.New(),
+		Name:     "Ana",
+		Email:    "ana@example.test",
+		TimeZone: "Europe/Madrid",
+		Locale:   "es",
+	}
+	n := &domain.Notification{
+		UserID:  user.ID,
+		Event:   string(EventBooked),
+		Channel: domain.ChannelEmail,
+		Payload: payload,
+	}
+
+	s := &NotificationService{templates: compileTemplates()}
+	msg, err := s.compose(n, user, &domain.NotificationPreference{})
+	if err != nil {
+		t.Fatalf("compose: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sender.Send(ctx, msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	var got smtpSession
+	select {
+	case got = <-sessions:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP server saw no complete conversation")
+	}
+
+	// Envelope
+	if got.from != "no-reply@appointment-booking.local" {
+		t.Errorf("MAIL FROM = %q", got.from)
+	}
+	if len(got.rcpt) != 1 || got.rcpt[0] != "ana@example.test" {
+		t.Errorf("RCPT TO = %v", got.rcpt)
+	}
+
+	// Headers
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(got.data)))
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("parse headers: %v", err)
+	}
+	wantHeaders := map[string]string{
+		"From":         "no-reply@appointment-booking.local",
+		"To":           "ana@example.test",
+		"Mime-Version": "1.0",
+		"Content-Type": "text/plain; charset=utf-8",
+	}
+	for key, want := range wantHeaders {
+		if got := header.Get(key); got != want {
+			t.Errorf("header %s = %q, want %q", key, got, want)
+		}
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decode subject: %v", err)
+	}
+	if subject != "Cita reservada: Depilación" {
+		t.Errorf("subject = %q", subject)
+	}
+	if _, err := time.Parse(time.RFC1123Z, header.Get("Date")); err != nil {
+		t.Errorf("date header %q: %v", header.Get("Date"), err)
+	}
+
+	// Body, rendered in Spanish and in the recipient's zone (CET in March)
+	// (the dot reader already turned the CRLF line endings back into LF)
+	body, err := io.ReadAll(reader.R)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	want := "Hola Ana,\n\nTu cita de Depilación el Sat, 14 Mar 2026 10:30 CET ha sido reservada.\n"
+	if string(body) != want {
+		t.Errorf("body =\n%q\nwant\n%q", body, want)
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"text/template"
+)
+
+type NotificationEvent string
+
+const (
+	EventBooked        NotificationEvent = "booked"
+	EventCancelled     NotificationEvent = "cancelled"
+	EventRescheduled   NotificationEvent = "rescheduled"
+	EventReminder      NotificationEvent = "reminder"
+	EventWaitlistOffer NotificationEvent = "waitlist_offer"
+	EventVerifyEmail   NotificationEvent = "verify_email"
+	EventPasswordReset NotificationEvent = "password_reset"
+)
+
+// IsUrgent reports whether the event must go out even during quiet hours
+func (e NotificationEvent) IsUrgent() bool {
+	return e == EventReminder || e == EventWaitlistOffer || e.IsAccount()
+}
+
+// IsAccount reports whether the event is an account security email. These always go
+// out by email, whatever the user's preferences say, and carry a single-use link.
+func (e NotificationEvent) IsAccount() bool {
+	return e == EventVerifyEmail || e == EventPasswordReset
+}
+
+func (e NotificationEvent) IsValid() bool {
+	_, ok := notificationTemplates[defaultLocale][e]
+	return ok
+}
+
+const defaultLocale = "en"
+
+// messageTemplate is the raw text of one event in one locale
+type messageTemplate struct {
+	Subject string
+	Body    string
+}
+
+// Fields are already formatted in the recipient's time zone
+type templateData struct {
+	Name      string
+	Service   string
+	Start     string
+	OldStart  string
+	Reason    string
+	ExpiresAt string
+	Link      string
+}
+
+var notificationTemplates = map[string]map[NotificationEvent]messageTemplate{
+	"en": {
+		EventBooked: {
+			Subject: "Appointment booked: {{.Service}}",
+			Body:    "Hi {{.Name}},\n\nYour {{.Service}} appointment on {{.Start}} has been booked.",
+		},
+		EventCancelled: {
+			Subject: "Appointment cancelled: {{.Service}}",
+			Body:    "Hi {{.Name}},\n\nYour {{.Service}} appointment on {{.Start}} has been cancelled.{{if .Reason}}\nReason: {{.Reason}}{{end}}",
+		},
+		EventRescheduled: {
+			Subject: "Appointment moved: {{.Service}}",
+			Body:    "Hi {{.Name}},\n\nYour {{.Service}} appointment has moved from {{.OldStart}} to {{.Start}}.",
+		},
+		EventReminder: {
+			Subject: "Reminder: {{.Service}} on {{.Start}}",
+			Body:    "Hi {{.Name}},\n\nThis is a reminder of your {{.Service}} appointment on {{.Start}}.",
+		},
+		EventWaitlistOffer: {
+			Subject: "A slot opened up: {{.Service}}",
+			Body:    "Hi {{.Name}},\n\nA {{.Service}} slot on {{.Start}} just opened up. Claim it before {{.ExpiresAt}} or it goes to the next person in line.",
+		},
+		EventVerifyEmail: {
+			Subject: "Confirm your email address",
+			Body:    "Hi {{.Name}},\n\nPlease confirm your email address by opening this link before {{.ExpiresAt}}:\n{{.Link}}\n\nIf you didn't create an account, you can ignore this email.",
+		},
+		EventPasswordReset: {
+			Subject: "Reset your password",
+			Body:    "Hi {{.Name}},\n\nSomeone asked to reset the password of your account. Open this link before {{.ExpiresAt}} to choose a new one:\n{{.Link}}\n\nIf it wasn't you, ignore this email; your password stays the same.",
+		},
+	},
+	"es": {
+		EventBooked: {
+			Subject: "Cita reservada: {{.Service}}",
+			Body:    "Hola {{.Name}},\n\nTu cita de {{.Service}} el {{.Start}} ha sido reservada.",
+		},
+		EventCancelled: {
+			Subject: "Cita cancelada: {{.Service}}",
+			Body:    "Hola {{.Name}},\n\nTu cita de {{.Service}} el {{.Start}} ha sido cancelada.{{if .Reason}}\nMotivo: {{.Reason}}{{end}}",
+		},
+		EventRescheduled: {
+			Subject: "Cita reprogramada: {{.Service}}",
+			Body:    "Hola {{.Name}},\n\nTu cita de {{.Service}} se ha movido del {{.OldStart}} al {{.Start}}.",
+		},
+		EventReminder: {
+			Subject: "Recordatorio: {{.Service}} el {{.Start}}",
+			Body:    "Hola {{.Name}},\n\nTe recordamos tu cita de {{.Service}} el {{.Start}}.",
+		},
+		EventWaitlistOffer: {
+			Subject: "Hay un hueco libre: {{.Service}}",
+			Body:    "Hola {{.Name}},\n\nSe ha liberado un hueco de {{.Service}} el {{.Start}}. Resérvalo antes del {{.ExpiresAt}} o pasará a la siguiente persona.",
+		},
+		EventVerifyEmail: {
+			Subject: "Confirma tu dirección de correo",
+			Body:    "Hola {{.Name}},\n\nConfirma tu dirección de correo abriendo este enlace antes del {{.ExpiresAt}}:\n{{.Link}}\n\nSi no has creado una cuenta, puedes ignorar este correo.",
+		},
+		EventPasswordReset: {
+			Subject: "Restablece tu contraseña",
+			Body:    "Hola {{.Name}},\n\nAlguien ha pedido restablecer la contraseña de tu cuenta. Abre este enlace antes del {{.ExpiresAt}} para elegir una nueva:\n{{.Link}}\n\nSi no has sido tú, ignora este correo; tu contraseña no cambia.",
+		},
+	},
+}
+
+// compiledTemplate holds the parsed subject and body of one event in one locale
+type compiledTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// compileTemplates parses every template up front so a typo fails at startup, not at send time
+func compileTemplates() map[string]map[NotificationEvent]compiledTemplate {
+	compiled := make(map[string]map[NotificationEvent]compiledTemplate, len(notificationTemplates))
+	for locale, events := range notificationTemplates {
+		compiled[locale] = make(map[NotificationEvent]compiledTemplate, len(events))
+		for event, t := range events {
+			name := locale + "/" + string(event)
+			compiled[locale][event] = compiledTemplate{
+				subject: template.Must(template.New(name + "/subject").Parse(t.Subject)),
+				body:    template.Must(template.New(name + "/body").Parse(t.Body)),
+			}
+		}
+	}
+	return compiled
+}
+
+// IsSupportedLocale reports whether notifications can be rendered in locale
+func IsSupportedLocale(locale string) bool {
+	_, ok := notificationTemplates[locale]
+	return ok
+}
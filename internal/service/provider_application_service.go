@@ -0,0 +1,143 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/repository"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrApplicationNotFound = errors.New("application not found")
+	ErrApplicationPending  = errors.New("you already have a pending application")
+	ErrApplicationReviewed = errors.New("application has already been reviewed")
+	ErrAlreadyProvider     = errors.New("account is already a provider")
+)
+
+// ProviderApplicationService runs the provider sign-up flow: a customer applies and an
+// admin approves (which makes them a provider) or rejects the application
+type ProviderApplicationService struct {
+	repo     *repository.ProviderApplicationRepository
+	userRepo *repository.UserRepository
+}
+
+func NewProviderApplicationService(repo *repository.ProviderApplicationRepository, userRepo *repository.UserRepository) *ProviderApplicationService {
+	return &ProviderApplicationService{repo: repo, userRepo: userRepo}
+}
+
+type ApplyInput struct {
+	Message string `json:"message" binding:"required,max=2000"`
+}
+
+type ApplicationQuery struct {
+	Status string `form:"status"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+type ReviewInput struct {
+	Note string `json:"note" binding:"max=2000"`
+}
+
+func (s *ProviderApplicationService) Apply(userID uuid.UUID, input ApplyInput) (*domain.ProviderApplication, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.Roles.Has(domain.RoleProvider) {
+		return nil, ErrAlreadyProvider
+	}
+
+	pending, err := s.repo.HasPending(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrApplicationPending
+	}
+
+	application := &domain.ProviderApplication{
+		UserID:  userID,
+		Message: input.Message,
+		Status:  domain.ApplicationPending,
+	}
+	if err := s.repo.Create(application); err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// Mine returns the user's latest application, so they can follow its status
+func (s *ProviderApplicationService) Mine(userID uuid.UUID) (*domain.ProviderApplication, error) {
+	application, err := s.repo.FindLatestByUser(userID)
+	if err != nil {
+		return nil, ErrApplicationNotFound
+	}
+	return application, nil
+}
+
+func (s *ProviderApplicationService) List(query ApplicationQuery) ([]domain.ProviderApplication, error) {
+	if query.Limit == 0 {
+		query.Limit = 50
+	}
+	return s.repo.List(domain.ProviderApplicationStatus(query.Status), query.Limit, query.Offset)
+}
+
+// Approve adds the provider role to the applicant, who keeps their other roles. Their
+// current access token doesn't carry it yet; it is picked up on the next refresh.
+func (s *ProviderApplicationService) Approve(reviewerID, applicationID uuid.UUID, input ReviewInput) (*domain.ProviderApplication, error) {
+	application, err := s.repo.FindByID(applicationID)
+	if err != nil {
+		return nil, ErrApplicationNotFound
+	}
+	applicant, err := s.userRepo.FindByID(application.UserID)
+	if err != nil {
+		return nil, errors.New("applicant no longer exists")
+	}
+
+	tx := s.repo.BeginTx()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	reviewed, err := s.repo.Review(tx, applicationID, domain.ApplicationApproved, reviewerID, input.Note)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !reviewed {
+		tx.Rollback()
+		return nil, ErrApplicationReviewed
+	}
+
+	if !applicant.Roles.Has(domain.RoleProvider) {
+		roles := append(slices.Clone(applicant.Roles), domain.RoleProvider)
+		if err := s.userRepo.UpdateRoles(tx, applicant.ID, roles); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(applicationID)
+}
+
+func (s *ProviderApplicationService) Reject(reviewerID, applicationID uuid.UUID, input ReviewInput) (*domain.ProviderApplication, error) {
+	reviewed, err := s.repo.Review(nil, applicationID, domain.ApplicationRejected, reviewerID, input.Note)
+	if err != nil {
+		return nil, err
+	}
+	if !reviewed {
+		if _, err := s.repo.FindByID(applicationID); err != nil {
+			return nil, ErrApplicationNotFound
+		}
+		return nil, ErrApplicationReviewed
+	}
+	return s.repo.FindByID(applicationID)
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/event"
+	"appointment-booking/internal/repository"
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Upper bound of reminders one instance claims per tick
+const reminderBatchSize = 100
+
+// ReminderService schedules reminders alongside appointment changes and sends them
+// from a background worker
+type ReminderService struct {
+	repo     *repository.ReminderRepository
+	apptRepo *repository.AppointmentRepository
+	notifier *NotificationService
+	offsets  []time.Duration
+}
+
+func NewReminderService(repo *repository.ReminderRepository, apptRepo *repository.AppointmentRepository, notifier *NotificationService, offsets []time.Duration) *ReminderService {
+	return &ReminderService{repo: repo, apptRepo: apptRepo, notifier: notifier, offsets: offsets}
+}
+
+// Schedule creates the appointment's reminders inside tx. Offsets whose send time has
+// already passed (e.g. a 24h reminder for a booking made 2h ahead) are skipped.
+func (s *ReminderService) Schedule(tx *gorm.DB, appt *domain.Appointment) error {
+	now := time.Now()
+
+	var reminders []domain.AppointmentReminder
+	for _, offset := range s.offsets {
+		sendAt := appt.StartTime.Add(-offset)
+		if !sendAt.After(now) {
+			continue
+		}
+		reminders = append(reminders, domain.AppointmentReminder{
+			AppointmentID: appt.ID,
+			OffsetMinutes: int(offset.Minutes()),
+			StartTime:     appt.StartTime,
+			SendAt:        sendAt,
+			Status:        domain.ReminderPending,
+		})
+	}
+
+	return s.repo.CreateBatch(tx, reminders)
+}
+
+// Reschedule drops the reminders for the old start time and schedules new ones
+func (s *ReminderService) Reschedule(tx *gorm.DB, appt *domain.Appointment) error {
+	if err := s.repo.CancelPending(tx, appt.ID); err != nil {
+		return err
+	}
+	return s.Schedule(tx, appt)
+}
+
+// Cancel drops the appointment's outstanding reminders
+func (s *ReminderService) Cancel(tx *gorm.DB, appt *domain.Appointment) error {
+	return s.repo.CancelPending(tx, appt.ID)
+}
+
+// Subscribe keeps reminders in step with the appointment inside the same transaction
+func (s *ReminderService) Subscribe(bus *event.Bus) {
+	event.OnTx(bus, func(tx *gorm.DB, e event.AppointmentBooked) error {
+		return s.Schedule(tx, e.Appointment)
+	})
+	event.OnTx(bus, func(tx *gorm.DB, e event.AppointmentRescheduled) error {
+		return s.Reschedule(tx, e.Appointment)
+	})
+	event.OnTx(bus, func(tx *gorm.DB, e event.AppointmentCancelled) error {
+		return s.Cancel(tx, e.Appointment)
+	})
+	event.OnTx(bus, func(tx *gorm.DB, e event.AppointmentStatusChanged) error {
+		if e.Appointment.Status.IsActive() {
+			return nil
+		}
+		return s.Cancel(tx, e.Appointment)
+	})
+}
+
+// StartWorker sends due reminders every interval until ctx is cancelled
+func (s *ReminderService) StartWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.SendDue()
+			}
+		}
+	}()
+}
+
+// SendDue sends every reminder whose time has come
+func (s *ReminderService) SendDue() {
+	now := time.Now()
+
+	if err := s.repo.MarkMissed(now); err != nil {
+		log.Printf("Reminder cleanup failed: %v", err)
+	}
+
+	for {
+		sent, err := s.sendBatch(now)
+		if err != nil {
+			log.Printf("Reminder batch failed: %v", err)
+			return
+		}
+		if sent < reminderBatchSize {
+			return
+		}
+	}
+}
+
+// sendBatch claims a batch and queues its notifications in one transaction, so a
+// reminder is either queued exactly once or stays pending for the next tick
+func (s *ReminderService) sendBatch(now time.Time) (int, error) {
+	tx := s.repo.BeginTx()
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	reminders, err := s.repo.ClaimDue(tx, now, reminderBatchSize)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	for _, reminder := range reminders {
+		if err := s.send(tx, reminder); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+	return len(reminders), nil
+}
+
+func (s *ReminderService) send(tx *gorm.DB, reminder domain.AppointmentReminder) error {
+	appt, err := s.apptRepo.FindByID(reminder.AppointmentID)
+	if err != nil {
+		log.Printf("Reminder %s: appointment not found", reminder.ID)
+		return nil
+	}
+
+	// Safety net in case a change slipped past Cancel/Reschedule
+	if !appt.Status.IsActive() || !appt.StartTime.Equal(reminder.StartTime) {
+		return nil
+	}
+
+	return s.notifier.Enqueue(tx, appt.CustomerID, EventReminder, NotificationData{
+		Service:   appt.ServiceType,
+		StartTime: appt.StartTime,
+	})
+}
@@ -17,6 +17,14 @@ type DashboardData struct {
 	TotalAppointments int64                            `json:"total_appointments"`
 	Breakdown         map[string]int64                 `json:"breakdown"`
 	TopProviders      []repository.ProviderLeaderboard `json:"top_providers"`
+	Cancellations     CancellationReport               `json:"cancellations"`
+}
+
+type CancellationReport struct {
+	Total    int64            `json:"total"`
+	Late     int64            `json:"late"`
+	ByActor  map[string]int64 `json:"by_actor"`  // "customer" / "provider"
+	ByReason map[string]int64 `json:"by_reason"` // Reason code, "UNSPECIFIED" when none was given
 }
 
 func (s *ReportService) GetDashboardStats() (*DashboardData, error) {
@@ -36,7 +44,13 @@ func (s *ReportService) GetDashboardStats() (*DashboardData, error) {
 		return nil, err
 	}
 
-	// 4. Format Data
+	// 4. Fetch Cancellations
+	cancellations, err := s.repo.GetCancellationStats(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. Format Data
 	breakdown := make(map[string]int64)
 	var total int64 = 0
 
@@ -45,9 +59,31 @@ func (s *ReportService) GetDashboardStats() (*DashboardData, error) {
 		total += s.Count
 	}
 
+	report := CancellationReport{
+		ByActor:  make(map[string]int64),
+		ByReason: make(map[string]int64),
+	}
+	for _, c := range cancellations {
+		report.Total += c.Count
+		if c.Late {
+			report.Late += c.Count
+		}
+		actor := c.CancelledBy
+		if actor == "" {
+			actor = "unknown"
+		}
+		report.ByActor[actor] += c.Count
+		reason := c.Reason
+		if reason == "" {
+			reason = "UNSPECIFIED"
+		}
+		report.ByReason[reason] += c.Count
+	}
+
 	return &DashboardData{
 		TotalAppointments: total,
 		Breakdown:         breakdown,
 		TopProviders:      topProviders,
+		Cancellations:     report,
 	}, nil
 }
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"errors"
+	"sort"
+	"time"
+)
+
+// timeWindow is a half-open [Start, End) interval on a concrete date
+type timeWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// parseClock parses a "15:04" wall-clock string
+func parseClock(value string) (time.Time, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return time.Time{}, errors.New("invalid time format (use HH:MM)")
+	}
+	return t, nil
+}
+
+// normalizeClockRange checks that both values are valid wall-clock times and start is
+// before end, and returns them zero-padded as "HH:MM". parseClock also accepts "9:00",
+// so only normalized values can be stored and compared as text.
+func normalizeClockRange(start, end string) (string, string, error) {
+	s, err := parseClock(start)
+	if err != nil {
+		return "", "", err
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return "", "", err
+	}
+	if !s.Before(e) {
+		return "", "", errors.New("start time must be before end time")
+	}
+	return s.Format("15:04"), e.Format("15:04"), nil
+}
+
+// overlapsWindow reports whether [start, end) overlaps any of the weekly windows. It
+// compares parsed clock times, so rows stored before values were normalized still match.
+func overlapsWindow(start, end string, windows []domain.Availability) bool {
+	s, errS := parseClock(start)
+	e, errE := parseClock(end)
+	if errS != nil || errE != nil {
+		return false
+	}
+	for _, w := range windows {
+		ws, err := parseClock(w.StartTime)
+		if err != nil {
+			continue
+		}
+		we, err := parseClock(w.EndTime)
+		if err != nil {
+			continue
+		}
+		if s.Before(we) && e.After(ws) {
+			return true
+		}
+	}
+	return false
+}
+
+// windowOnDate anchors a "09:00"-"17:00" pair to the given date as wall-clock time
+// in loc. time.Date normalizes clock times skipped by a DST jump forward, and picks
+// the first occurrence of repeated ones, so windows stay correct across transitions.
+func windowOnDate(date time.Time, start, end string, loc *time.Location) (timeWindow, error) {
+	s, err := parseClock(start)
+	if err != nil {
+		return timeWindow{}, err
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return timeWindow{}, err
+	}
+
+	return timeWindow{
+		Start: time.Date(date.Year(), date.Month(), date.Day(), s.Hour(), s.Minute(), 0, 0, loc),
+		End:   time.Date(date.Year(), date.Month(), date.Day(), e.Hour(), e.Minute(), 0, 0, loc),
+	}, nil
+}
+
+var ErrInvalidTimeZone = errors.New("invalid time zone (use an IANA name like Europe/Berlin)")
+
+// loadLocation resolves an IANA zone name, treating empty as UTC
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+	return loc, nil
+}
+
+// mergeWindows sorts windows and joins any that overlap or touch
+func mergeWindows(windows []timeWindow) []timeWindow {
+	if len(windows) == 0 {
+		return nil
+	}
+
+	sorted := make([]timeWindow, len(windows))
+	copy(sorted, windows)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	merged := []timeWindow{sorted[0]}
+	for _, w := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if !w.Start.After(last.End) {
+			if w.End.After(last.End) {
+				last.End = w.End
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+	return merged
+}
+
+// subtractWindow removes the blocked interval from every window
+func subtractWindow(windows []timeWindow, blocked timeWindow) []timeWindow {
+	var result []timeWindow
+	for _, w := range windows {
+		// No overlap: keep as is
+		if !w.Start.Before(blocked.End) || !w.End.After(blocked.Start) {
+			result = append(result, w)
+			continue
+		}
+		if w.Start.Before(blocked.Start) {
+			result = append(result, timeWindow{Start: w.Start, End: blocked.Start})
+		}
+		if w.End.After(blocked.End) {
+			result = append(result, timeWindow{Start: blocked.End, End: w.End})
+		}
+	}
+	return result
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"testing"
+)
+
+func TestNormalizeClockRangePadsHours(t *testing.T) {
+	start, end, err := normalizeClockRange("9:00", "10:00")
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if start != "09:00" || end != "10:00" {
+		t.Fatalf("got %q-%q, want 09:00-10:00", start, end)
+	}
+}
+
+func TestOverlapsWindowWithUnpaddedHour(t *testing.T) {
+	existing := []domain.Availability{{StartTime: "08:00", EndTime: "09:30"}}
+
+	// "9:00" sorts after "09:30" as text but is inside the window
+	if !overlapsWindow("9:00", "10:00", existing) {
+		t.Error("9:00-10:00 should overlap 08:00-09:30")
+	}
+	// Rows stored before normalization may be unpadded too
+	legacy := []domain.Availability{{StartTime: "8:00", EndTime: "9:30"}}
+	if !overlapsWindow("09:00", "10:00", legacy) {
+		t.Error("09:00-10:00 should overlap 8:00-9:30")
+	}
+	if overlapsWindow("9:30", "10:00", existing) {
+		t.Error("9:30-10:00 only touches 08:00-09:30 and should not overlap")
+	}
+}
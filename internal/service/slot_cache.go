@@ -0,0 +1,86 @@
+package service
+
+import (
+	"appointment-booking/internal/event"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// SlotCache drops cached slot lists whenever the bookable time behind them changes
+type SlotCache struct {
+	redis *redis.Client
+}
+
+func NewSlotCache(redis *redis.Client) *SlotCache {
+	return &SlotCache{redis: redis}
+}
+
+func (c *SlotCache) Subscribe(bus *event.Bus) {
+	event.On(bus, func(e event.AppointmentBooked) {
+		invalidateSlotCacheAround(c.redis, e.Appointment.ProviderID, e.Appointment.StartTime)
+	})
+	event.On(bus, func(e event.AppointmentCancelled) {
+		invalidateSlotCacheAround(c.redis, e.Appointment.ProviderID, e.Appointment.StartTime)
+	})
+	event.On(bus, func(e event.AppointmentRescheduled) {
+		invalidateSlotCacheAround(c.redis, e.Appointment.ProviderID, e.OldStartTime)
+		invalidateSlotCacheAround(c.redis, e.Appointment.ProviderID, e.Appointment.StartTime)
+	})
+	event.On(bus, func(e event.AppointmentStatusChanged) {
+		if !e.Appointment.Status.IsActive() {
+			invalidateSlotCacheAround(c.redis, e.Appointment.ProviderID, e.Appointment.StartTime)
+		}
+	})
+	event.On(bus, func(e event.AvailabilityChanged) {
+		if e.Date == "" {
+			invalidateProviderSlotCache(c.redis, e.ProviderID)
+		} else {
+			invalidateSlotCache(c.redis, e.ProviderID, e.Date)
+		}
+	})
+}
+
+// slotCacheKey builds the key for cached slots, e.g. "slots:<provider>:2025-10-30[:<service>]"
+func slotCacheKey(providerID uuid.UUID, dateStr string, serviceID *uuid.UUID) string {
+	key := fmt.Sprintf("slots:%s:%s", providerID.String(), dateStr)
+	if serviceID != nil {
+		key += ":" + serviceID.String()
+	}
+	return key
+}
+
+// invalidateSlotCache drops every cached slot list for the provider on that date.
+// Errors are ignored; if Redis is down the entries just expire naturally later.
+func invalidateSlotCache(client *redis.Client, providerID uuid.UUID, dateStr string) {
+	ctx := context.Background()
+	base := slotCacheKey(providerID, dateStr, nil)
+
+	client.Del(ctx, base)
+	deleteByPattern(ctx, client, base+":*")
+}
+
+// invalidateSlotCacheAround drops cached slots for every date that instant t can fall
+// on in some time zone (the UTC date and its neighbours). Cache keys use the provider's
+// local date, which callers holding only an appointment don't know.
+func invalidateSlotCacheAround(client *redis.Client, providerID uuid.UUID, t time.Time) {
+	utc := t.UTC()
+	for _, offset := range []int{-1, 0, 1} {
+		invalidateSlotCache(client, providerID, utc.AddDate(0, 0, offset).Format("2006-01-02"))
+	}
+}
+
+// invalidateProviderSlotCache drops every cached date for the provider
+func invalidateProviderSlotCache(client *redis.Client, providerID uuid.UUID) {
+	deleteByPattern(context.Background(), client, fmt.Sprintf("slots:%s:*", providerID.String()))
+}
+
+func deleteByPattern(ctx context.Context, client *redis.Client, pattern string) {
+	iter := client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		client.Del(ctx, iter.Val())
+	}
+}
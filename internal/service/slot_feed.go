@@ -0,0 +1,102 @@
+package service
+
+import (
+	"appointment-booking/internal/event"
+	"appointment-booking/internal/repository"
+	"appointment-booking/internal/websocket"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SlotFeed pushes live availability deltas to clients subscribed to a provider+date
+// topic, so an open slot page can update without refetching
+type SlotFeed struct {
+	ws       *websocket.Handler
+	userRepo *repository.UserRepository
+}
+
+func NewSlotFeed(ws *websocket.Handler, userRepo *repository.UserRepository) *SlotFeed {
+	return &SlotFeed{ws: ws, userRepo: userRepo}
+}
+
+// Subscribe turns appointment and availability changes into slot deltas
+func (f *SlotFeed) Subscribe(bus *event.Bus) {
+	event.On(bus, func(e event.AppointmentBooked) {
+		f.Taken(e.Appointment.ProviderID, e.Appointment.BlockStart, e.Appointment.BlockEnd)
+	})
+	event.On(bus, func(e event.AppointmentCancelled) {
+		f.Freed(e.Appointment.ProviderID, e.Appointment.BlockStart, e.Appointment.BlockEnd)
+	})
+	event.On(bus, func(e event.AppointmentRescheduled) {
+		f.Freed(e.Appointment.ProviderID, e.OldBlockStart, e.OldBlockEnd)
+		f.Taken(e.Appointment.ProviderID, e.Appointment.BlockStart, e.Appointment.BlockEnd)
+	})
+	event.On(bus, func(e event.AppointmentStatusChanged) {
+		if !e.Appointment.Status.IsActive() {
+			f.Freed(e.Appointment.ProviderID, e.Appointment.BlockStart, e.Appointment.BlockEnd)
+		}
+	})
+	event.On(bus, func(e event.AvailabilityChanged) {
+		if e.Date == "" {
+			f.ScheduleChanged(e.ProviderID)
+		} else {
+			f.DayChanged(e.ProviderID, e.Date)
+		}
+	})
+}
+
+// Taken announces that [blockStart, blockEnd) is no longer bookable
+func (f *SlotFeed) Taken(providerID uuid.UUID, blockStart, blockEnd time.Time) {
+	f.publishRange("slot_taken", providerID, blockStart, blockEnd)
+}
+
+// Freed announces that [blockStart, blockEnd) became bookable again
+func (f *SlotFeed) Freed(providerID uuid.UUID, blockStart, blockEnd time.Time) {
+	f.publishRange("slot_freed", providerID, blockStart, blockEnd)
+}
+
+// DayChanged tells subscribers of one date to refetch, e.g. after an override
+func (f *SlotFeed) DayChanged(providerID uuid.UUID, date string) {
+	f.ws.PublishTopic(map[string]interface{}{
+		"event":       "availability_changed",
+		"provider_id": providerID,
+		"date":        date,
+	}, websocket.SlotTopic(providerID, date))
+}
+
+// ScheduleChanged tells subscribers of every date of the provider to refetch
+func (f *SlotFeed) ScheduleChanged(providerID uuid.UUID) {
+	f.ws.PublishProvider(map[string]interface{}{
+		"event":       "availability_changed",
+		"provider_id": providerID,
+	}, providerID)
+}
+
+// publishRange sends the delta to the topics of every provider-local date the range touches
+func (f *SlotFeed) publishRange(event string, providerID uuid.UUID, blockStart, blockEnd time.Time) {
+	provider, err := f.userRepo.FindByID(providerID)
+	if err != nil {
+		log.Printf("Slot feed: provider %s not found", providerID)
+		return
+	}
+	loc, err := loadLocation(provider.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	first := blockStart.In(loc).Format("2006-01-02")
+	last := blockEnd.Add(-time.Nanosecond).In(loc).Format("2006-01-02")
+	topics := []string{websocket.SlotTopic(providerID, first)}
+	if last != first {
+		topics = append(topics, websocket.SlotTopic(providerID, last))
+	}
+
+	f.ws.PublishTopic(map[string]interface{}{
+		"event":       event,
+		"provider_id": providerID,
+		"start_time":  blockStart,
+		"end_time":    blockEnd,
+	}, topics...)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/repository"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrSelfModification = errors.New("admins cannot change the roles or status of their own account")
+)
+
+// UserAdminService lets admins manage accounts. Every change that takes rights away
+// also ends the user's sessions, so it applies immediately rather than when their
+// access token expires.
+type UserAdminService struct {
+	repo *repository.UserRepository
+	auth *AuthService
+}
+
+func NewUserAdminService(repo *repository.UserRepository, auth *AuthService) *UserAdminService {
+	return &UserAdminService{repo: repo, auth: auth}
+}
+
+type UserQuery struct {
+	Role      string `form:"role"`
+	Suspended *bool  `form:"suspended"`
+	Q         string `form:"q"` // Name or email contains
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset    int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// SetRolesInput is the complete new set of roles, e.g. ["customer", "staff"]
+type SetRolesInput struct {
+	Roles []string `json:"roles" binding:"required,min=1"`
+}
+
+func (s *UserAdminService) List(query UserQuery) ([]domain.User, error) {
+	filter := repository.UserFilter{
+		Role:      domain.UserRole(query.Role),
+		Suspended: query.Suspended,
+		Query:     query.Q,
+		Limit:     query.Limit,
+		Offset:    query.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 50
+	}
+	return s.repo.List(filter)
+}
+
+// SetRoles promotes or demotes a user by replacing their roles
+func (s *UserAdminService) SetRoles(adminID, userID uuid.UUID, input SetRolesInput) (*domain.User, error) {
+	var roles domain.UserRoles
+	for _, name := range input.Roles {
+		role := domain.UserRole(name)
+		if !role.IsValid() {
+			return nil, ErrInvalidRole
+		}
+		if !roles.Has(role) {
+			roles = append(roles, role)
+		}
+	}
+
+	user, err := s.findOther(adminID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateRoles(nil, userID, roles); err != nil {
+		return nil, err
+	}
+	if err := s.auth.LogoutAll(userID); err != nil {
+		return nil, err
+	}
+
+	user.Roles = roles
+	return user, nil
+}
+
+// Suspend blocks sign-in and ends every session. Appointments are left as they are.
+func (s *UserAdminService) Suspend(adminID, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.findOther(adminID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.SuspendedAt != nil {
+		return user, nil
+	}
+
+	now := time.Now()
+	if err := s.repo.SetSuspendedAt(userID, &now); err != nil {
+		return nil, err
+	}
+	if err := s.auth.LogoutAll(userID); err != nil {
+		return nil, err
+	}
+
+	user.SuspendedAt = &now
+	return user, nil
+}
+
+func (s *UserAdminService) Reactivate(adminID, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.findOther(adminID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.SuspendedAt == nil {
+		return user, nil
+	}
+
+	if err := s.repo.SetSuspendedAt(userID, nil); err != nil {
+		return nil, err
+	}
+
+	user.SuspendedAt = nil
+	return user, nil
+}
+
+// Delete soft-deletes the user (DeletedAt), so their appointments and history keep
+// pointing at a real row
+func (s *UserAdminService) Delete(adminID, userID uuid.UUID) error {
+	if _, err := s.findOther(adminID, userID); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(userID); err != nil {
+		return err
+	}
+	return s.auth.LogoutAll(userID)
+}
+
+// findOther loads the target user, refusing to let an admin lock themselves out
+func (s *UserAdminService) findOther(adminID, userID uuid.UUID) (*domain.User, error) {
+	if adminID == userID {
+		return nil, ErrSelfModification
+	}
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/event"
+	"appointment-booking/internal/repository"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// UserService handles a signed-in user's own profile settings
+type UserService struct {
+	repo *repository.UserRepository
+	bus  *event.Bus
+}
+
+func NewUserService(repo *repository.UserRepository, bus *event.Bus) *UserService {
+	return &UserService{repo: repo, bus: bus}
+}
+
+// Only non-nil fields are updated
+type UpdateSettingsInput struct {
+	TimeZone *string `json:"time_zone"`
+	Locale   *string `json:"locale"`
+}
+
+var ErrUnsupportedLocale = errors.New("unsupported locale")
+
+func (s *UserService) UpdateSettings(userID uuid.UUID, input UpdateSettingsInput) (*domain.User, error) {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	zoneChanged := false
+	if input.TimeZone != nil {
+		loc, err := loadLocation(*input.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		zoneChanged = user.TimeZone != loc.String()
+		user.TimeZone = loc.String()
+	}
+
+	if input.Locale != nil {
+		if !IsSupportedLocale(*input.Locale) {
+			return nil, ErrUnsupportedLocale
+		}
+		user.Locale = *input.Locale
+	}
+
+	if err := s.repo.UpdateSettings(user.ID, user.TimeZone, user.Locale); err != nil {
+		return nil, err
+	}
+
+	// A provider's zone decides how their weekly windows map to real instants
+	if zoneChanged && user.Roles.Has(domain.RoleProvider) {
+		s.bus.Publish(event.AvailabilityChanged{ProviderID: user.ID})
+	}
+
+	return user, nil
+}
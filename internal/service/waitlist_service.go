@@ -0,0 +1,354 @@
+package service
+
+import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/event"
+	"appointment-booking/internal/repository"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrSlotsAvailable is returned when joining the waitlist for a day that can still be booked
+var ErrSlotsAvailable = errors.New("this day still has free slots, book one instead")
+
+// WaitlistService queues customers for fully booked days and offers freed slots
+// to them one at a time. An offer is backed by a slot hold, so nobody else can
+// book the slot while the offered customer decides.
+type WaitlistService struct {
+	repo        *repository.WaitlistRepository
+	holdRepo    *repository.HoldRepository
+	apptRepo    *repository.AppointmentRepository
+	serviceRepo *repository.ServiceRepository
+	userRepo    *repository.UserRepository
+	holds       *HoldService
+	avail       *AvailabilityService
+	notifier    *NotificationService
+	bus         *event.Bus
+	offerTTL    time.Duration
+}
+
+func NewWaitlistService(repo *repository.WaitlistRepository, holdRepo *repository.HoldRepository, apptRepo *repository.AppointmentRepository, serviceRepo *repository.ServiceRepository, userRepo *repository.UserRepository, holds *HoldService, avail *AvailabilityService, notifier *NotificationService, bus *event.Bus, offerTTL time.Duration) *WaitlistService {
+	return &WaitlistService{
+		repo:        repo,
+		holdRepo:    holdRepo,
+		apptRepo:    apptRepo,
+		serviceRepo: serviceRepo,
+		userRepo:    userRepo,
+		holds:       holds,
+		avail:       avail,
+		notifier:    notifier,
+		bus:         bus,
+		offerTTL:    offerTTL,
+	}
+}
+
+type JoinWaitlistInput struct {
+	ProviderID string `json:"provider_id" binding:"required"`
+	ServiceID  string `json:"service_id" binding:"required"`
+	Date       string `json:"date" binding:"required"` // "2025-10-30", provider's local date
+}
+
+func (s *WaitlistService) Join(customerID uuid.UUID, input JoinWaitlistInput) (*domain.WaitlistEntry, error) {
+	providerID, err := uuid.Parse(input.ProviderID)
+	if err != nil {
+		return nil, errors.New("invalid provider ID")
+	}
+	serviceID, err := uuid.Parse(input.ServiceID)
+	if err != nil {
+		return nil, errors.New("invalid service ID")
+	}
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		return nil, errors.New("invalid date format (use YYYY-MM-DD)")
+	}
+
+	offered, err := s.serviceRepo.IsOfferedBy(serviceID, providerID)
+	if err != nil {
+		return nil, err
+	}
+	if !offered {
+		return nil, errors.New("provider does not offer this service")
+	}
+
+	loc, err := s.providerLocation(providerID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().In(loc)
+	if date.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+		return nil, errors.New("cannot join the waitlist for a past date")
+	}
+
+	// The waitlist is for fully booked days
+	slots, err := s.avail.GetAvailableSlots(providerID, input.Date, &serviceID, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(slots) > 0 {
+		return nil, ErrSlotsAvailable
+	}
+
+	exists, err := s.repo.ExistsOpen(customerID, providerID, serviceID, date)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("already on the waitlist for this day")
+	}
+
+	entry := &domain.WaitlistEntry{
+		CustomerID: customerID,
+		ProviderID: providerID,
+		ServiceID:  serviceID,
+		Date:       date,
+		Status:     domain.WaitlistWaiting,
+	}
+	if err := s.repo.Create(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *WaitlistService) ListMine(customerID uuid.UUID) ([]domain.WaitlistEntry, error) {
+	return s.repo.ListByCustomer(customerID)
+}
+
+// Leave removes the customer from the waitlist. An outstanding offer is released
+// and passed on to the next customer.
+func (s *WaitlistService) Leave(customerID, entryID uuid.UUID) error {
+	entry, err := s.findOwnEntry(customerID, entryID)
+	if err != nil {
+		return err
+	}
+
+	switch entry.Status {
+	case domain.WaitlistWaiting:
+		ok, err := s.repo.CompareAndSetStatus(entry.ID, domain.WaitlistWaiting, domain.WaitlistCancelled)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("waitlist entry changed, please retry")
+		}
+		return nil
+	case domain.WaitlistOffered:
+		ok, err := s.repo.CompareAndSetStatus(entry.ID, domain.WaitlistOffered, domain.WaitlistCancelled)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("waitlist entry changed, please retry")
+		}
+		s.passOn(entry)
+		return nil
+	default:
+		return errors.New("waitlist entry is no longer open")
+	}
+}
+
+// Claim books the offered slot for the customer
+func (s *WaitlistService) Claim(actor domain.Actor, entryID uuid.UUID) (*domain.Appointment, error) {
+	entry, err := s.findOwnEntry(actor.ID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != domain.WaitlistOffered || entry.HoldID == nil {
+		return nil, errors.New("there is no open offer for this entry")
+	}
+	if entry.OfferExpiresAt != nil && time.Now().After(*entry.OfferExpiresAt) {
+		return nil, errors.New("the offer has expired")
+	}
+
+	appointment, err := s.holds.ConfirmHold(actor, *entry.HoldID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.CompareAndSetStatus(entry.ID, domain.WaitlistOffered, domain.WaitlistClaimed); err != nil {
+		log.Printf("Failed to mark waitlist entry %s claimed: %v", entry.ID, err)
+	}
+	return appointment, nil
+}
+
+// Subscribe offers every block freed by a cancellation or reschedule to the waitlist
+func (s *WaitlistService) Subscribe(bus *event.Bus) {
+	event.On(bus, func(e event.AppointmentCancelled) {
+		go s.OfferFreedSlot(e.Appointment.ProviderID, e.Appointment.BlockStart, e.Appointment.BlockEnd)
+	})
+	event.On(bus, func(e event.AppointmentRescheduled) {
+		go s.OfferFreedSlot(e.Appointment.ProviderID, e.OldBlockStart, e.OldBlockEnd)
+	})
+}
+
+// OfferFreedSlot offers [blockStart, blockEnd) of a provider's calendar, just freed by a
+// cancellation or reschedule, to the first waiting customer whose service fits in it.
+func (s *WaitlistService) OfferFreedSlot(providerID uuid.UUID, blockStart, blockEnd time.Time) {
+	if !blockStart.After(time.Now()) {
+		return
+	}
+
+	loc, err := s.providerLocation(providerID)
+	if err != nil {
+		log.Printf("Waitlist: %v", err)
+		return
+	}
+	local := blockStart.In(loc)
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	entries, err := s.repo.ListWaiting(providerID, date)
+	if err != nil {
+		log.Printf("Waitlist lookup failed for provider %s: %v", providerID, err)
+		return
+	}
+
+	for i := range entries {
+		if s.offer(&entries[i], blockStart, blockEnd) {
+			return
+		}
+	}
+}
+
+// ExpireOffers passes every timed-out offer to the next customer in line
+func (s *WaitlistService) ExpireOffers() {
+	entries, err := s.repo.ListExpiredOffers(time.Now())
+	if err != nil {
+		log.Printf("Waitlist expiry lookup failed: %v", err)
+		return
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		// Only one instance wins the transition, so an offer is passed on exactly once
+		ok, err := s.repo.CompareAndSetStatus(entry.ID, domain.WaitlistOffered, domain.WaitlistExpired)
+		if err != nil || !ok {
+			continue
+		}
+		s.passOn(entry)
+	}
+}
+
+// StartExpiryWorker runs ExpireOffers periodically until ctx is cancelled
+func (s *WaitlistService) StartExpiryWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.ExpireOffers()
+			}
+		}
+	}()
+}
+
+// passOn releases the entry's hold and re-offers its freed range to the next customer
+func (s *WaitlistService) passOn(entry *domain.WaitlistEntry) {
+	if entry.HoldID != nil {
+		if hold, err := s.holdRepo.FindByID(context.Background(), *entry.HoldID); err == nil {
+			_ = s.holdRepo.Delete(context.Background(), hold)
+		}
+	}
+	if entry.FreedStart != nil && entry.FreedEnd != nil {
+		s.OfferFreedSlot(entry.ProviderID, *entry.FreedStart, *entry.FreedEnd)
+	}
+}
+
+// offer tries to hold the freed range for the entry's customer. It returns true if
+// the offer was made (or the range is gone), meaning nobody further down should get it.
+func (s *WaitlistService) offer(entry *domain.WaitlistEntry, freedStart, freedEnd time.Time) bool {
+	svc, err := s.serviceRepo.FindByID(entry.ServiceID)
+	if err != nil {
+		return false
+	}
+
+	start := freedStart.Add(svc.BufferBefore())
+	end := start.Add(svc.Duration())
+	blockEnd := end.Add(svc.BufferAfter())
+	if blockEnd.After(freedEnd) {
+		return false // Service doesn't fit; someone later in the queue may need less time
+	}
+
+	hasOverlap, err := s.apptRepo.HasOverlap(nil, entry.ProviderID, freedStart, blockEnd, nil)
+	if err != nil || hasOverlap {
+		return true // Slot was re-booked in the meantime
+	}
+
+	// Claim the entry first so concurrent offers can't pick the same customer
+	ok, err := s.repo.CompareAndSetStatus(entry.ID, domain.WaitlistWaiting, domain.WaitlistOffered)
+	if err != nil || !ok {
+		return false
+	}
+
+	expiresAt := time.Now().Add(s.offerTTL)
+	hold := &domain.SlotHold{
+		ID:         uuid.New(),
+		CustomerID: entry.CustomerID,
+		ProviderID: entry.ProviderID,
+		ServiceID:  entry.ServiceID,
+		StartTime:  start,
+		EndTime:    end,
+		BlockStart: freedStart,
+		BlockEnd:   blockEnd,
+		ExpiresAt:  expiresAt,
+	}
+	// Offers don't count against the customer's hold limit; the waitlist hands them out
+	if err := s.holdRepo.Create(context.Background(), hold, 0); err != nil {
+		// Someone holds it already; put the customer back in line
+		_, _ = s.repo.CompareAndSetStatus(entry.ID, domain.WaitlistOffered, domain.WaitlistWaiting)
+		return true
+	}
+
+	entry.Status = domain.WaitlistOffered
+	entry.HoldID = &hold.ID
+	entry.OfferedStart = &start
+	entry.OfferExpiresAt = &expiresAt
+	entry.FreedStart = &freedStart
+	entry.FreedEnd = &freedEnd
+	if err := s.repo.Update(entry); err != nil {
+		log.Printf("Failed to save waitlist offer %s: %v", entry.ID, err)
+		return true
+	}
+
+	err = s.notifier.Enqueue(nil, entry.CustomerID, EventWaitlistOffer, NotificationData{
+		Service:   svc.Name,
+		StartTime: start,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		log.Printf("Failed to queue waitlist offer %s: %v", entry.ID, err)
+	}
+	s.bus.Publish(event.WaitlistOfferMade{
+		EntryID:    entry.ID,
+		CustomerID: entry.CustomerID,
+		ProviderID: entry.ProviderID,
+		Slot:       start,
+		ExpiresAt:  expiresAt,
+	})
+	return true
+}
+
+func (s *WaitlistService) findOwnEntry(customerID, entryID uuid.UUID) (*domain.WaitlistEntry, error) {
+	entry, err := s.repo.FindByID(entryID)
+	if err != nil {
+		return nil, errors.New("waitlist entry not found")
+	}
+	if entry.CustomerID != customerID {
+		return nil, errors.New("unauthorized to modify this waitlist entry")
+	}
+	return entry, nil
+}
+
+func (s *WaitlistService) providerLocation(providerID uuid.UUID) (*time.Location, error) {
+	provider, err := s.userRepo.FindByID(providerID)
+	if err != nil {
+		return nil, errors.New("provider not found")
+	}
+	return loadLocation(provider.TimeZone)
+}
@@ -0,0 +1,118 @@
+package websocket
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Time allowed to write a message to the peer
+	writeWait = 10 * time.Second
+
+	// Time allowed to read the next pong message from the peer (Handler.pongWait)
+	defaultPongWait = 60 * time.Second
+
+	// Send pings to peer with this period. Must be less than pongWait.
+	defaultPingPeriod = (defaultPongWait * 9) / 10
+
+	// Maximum message size allowed from peer
+	maxMessageSize = 4096
+
+	// Messages queued per client before it counts as a slow consumer and is dropped
+	sendQueueSize = 64
+)
+
+// client is one authenticated connection. Only writePump writes to conn and only
+// readPump reads from it, as gorilla/websocket requires.
+type client struct {
+	hub       *Handler
+	conn      *websocket.Conn
+	userID    uuid.UUID
+	sessionID uuid.UUID // Login session of the token; the connection ends when it is revoked
+
+	// Receives events addressed to admins, i.e. may read all appointments
+	seesAll bool
+
+	// Outbound messages; closed by the hub when the client is unregistered
+	send chan []byte
+
+	// Subscribed topics; only touched by the hub goroutine
+	topics map[string]bool
+
+	// While reconnecting, live events wait in held until the replay is queued; only
+	// touched by the hub goroutine
+	replaying bool
+	held      [][]byte
+}
+
+func newClient(hub *Handler, conn *websocket.Conn, userID, sessionID uuid.UUID, seesAll bool) *client {
+	return &client{
+		hub:       hub,
+		conn:      conn,
+		userID:    userID,
+		sessionID: sessionID,
+		seesAll:   seesAll,
+		send:      make(chan []byte, sendQueueSize),
+		topics:    make(map[string]bool),
+	}
+}
+
+// readPump handles subscribe/unsubscribe messages and watches for pongs and closure.
+// A client that stops answering pings is dropped once the read deadline passes.
+func (c *client) readPump() {
+	defer func() {
+		c.hub.unregister <- c
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(c.hub.pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(c.hub.pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Println("WS Read Error:", err)
+			}
+			return
+		}
+		c.hub.subscriptions <- parseSubscription(c, data)
+	}
+}
+
+// writePump sends queued messages and periodic pings, each under a write deadline
+func (c *client) writePump() {
+	ticker := time.NewTicker(c.hub.pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				// The hub closed the queue
+				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				log.Println("WS Write Error:", err)
+				return
+			}
+
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Events kept per user for replay; a client further behind has to refetch
+	eventLogSize = 100
+
+	// A user's log is dropped after this long without new events
+	eventLogTTL = 24 * time.Hour
+
+	eventLogTimeout = 2 * time.Second
+)
+
+// registration adds a client to the hub. With since >= 0 the client is reconnecting:
+// the hub holds back its live events until the replay of what it missed (see attach)
+// arrives, so the client sees its events in seq order.
+type registration struct {
+	client *client
+	since  int64
+}
+
+// replay carries the events a reconnecting client missed, fetched from Redis outside
+// the hub loop
+type replay struct {
+	client   *client
+	since    int64
+	payloads [][]byte
+}
+
+// recordEventScript atomically takes the user's next seq, stamps it into the payload
+// and appends the result to the bounded log.
+// KEYS[1] = seq counter, KEYS[2] = log (sorted set scored by seq)
+// ARGV[1] = payload JSON object, ARGV[2] = log size, ARGV[3] = log TTL in ms
+var recordEventScript = redis.NewScript(`
+local seq = redis.call('INCR', KEYS[1])
+local body = string.sub(ARGV[1], 2)
+local entry
+if body == '}' then
+	entry = '{"seq":' .. seq .. '}'
+else
+	entry = '{"seq":' .. seq .. ',' .. body
+end
+redis.call('ZADD', KEYS[2], seq, entry)
+redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -(tonumber(ARGV[2]) + 1))
+redis.call('PEXPIRE', KEYS[2], ARGV[3])
+return entry
+`)
+
+func seqKey(userID uuid.UUID) string {
+	return "ws:seq:" + userID.String()
+}
+
+func eventLogKey(userID uuid.UUID) string {
+	return "ws:log:" + userID.String()
+}
+
+// userLock serializes taking a user's seq with queueing and relaying the event, so
+// this instance hands a user's events to the hubs in seq order. Users share a fixed
+// set of locks.
+func (h *Handler) userLock(userID uuid.UUID) *sync.Mutex {
+	return &h.userLocks[int(userID[15])%len(h.userLocks)]
+}
+
+// record gives the payload the user's next sequence number and stores it for replay.
+// Without Redis, or if Redis fails, the payload goes out without a seq. Call it with
+// the user's lock held.
+func (h *Handler) record(userID uuid.UUID, payload []byte) []byte {
+	if h.redis == nil || !bytes.HasPrefix(payload, []byte("{")) {
+		return payload
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), eventLogTimeout)
+	defer cancel()
+
+	keys := []string{seqKey(userID), eventLogKey(userID)}
+	entry, err := recordEventScript.Run(ctx, h.redis, keys, payload, eventLogSize, eventLogTTL.Milliseconds()).Text()
+	if err != nil {
+		log.Println("WS Event Log Error:", err)
+		return payload
+	}
+	return []byte(entry)
+}
+
+// payloadSeq returns the seq stamped into an event, or 0 for events without one
+func payloadSeq(payload []byte) int64 {
+	var stamped struct {
+		Seq int64 `json:"seq"`
+	}
+	if json.Unmarshal(payload, &stamped) != nil {
+		return 0
+	}
+	return stamped.Seq
+}
+
+// missedEvents returns the user's events after seq since, oldest first. If some of
+// them already fell out of the log, the client gets a single resync_required event
+// and should refetch its state over HTTP.
+func (h *Handler) missedEvents(userID uuid.UUID, since int64) [][]byte {
+	if h.redis == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), eventLogTimeout)
+	defer cancel()
+
+	current, err := h.redis.Get(ctx, seqKey(userID)).Int64()
+	if err == redis.Nil {
+		current = 0
+	} else if err != nil {
+		log.Println("WS Event Log Error:", err)
+		return [][]byte{resyncRequired(since, 0)}
+	}
+	if since >= current {
+		if since > current {
+			// The client knows a seq we never issued; its state can't be trusted
+			return [][]byte{resyncRequired(since, current)}
+		}
+		return nil
+	}
+
+	entries, err := h.redis.ZRangeByScoreWithScores(ctx, eventLogKey(userID), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(since, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		log.Println("WS Event Log Error:", err)
+		return [][]byte{resyncRequired(since, current)}
+	}
+
+	// The first missed event must still be in the log, otherwise there is a gap
+	if len(entries) == 0 || int64(entries[0].Score) != since+1 {
+		return [][]byte{resyncRequired(since, current)}
+	}
+
+	payloads := make([][]byte, 0, len(entries))
+	for _, e := range entries {
+		payloads = append(payloads, []byte(fmt.Sprint(e.Member)))
+	}
+	return payloads
+}
+
+// attach registers a client and, for a reconnect (since >= 0), replays what it missed.
+// The client is registered before the log is read, so an event recorded meanwhile is
+// either in the replay or held back by the hub, never lost. The Redis read runs on the
+// caller's goroutine; the hub keeps serving everyone else.
+func (h *Handler) attach(cl *client, since int64) {
+	h.register <- registration{client: cl, since: since}
+	if since >= 0 {
+		h.replays <- replay{client: cl, since: since, payloads: h.missedEvents(cl.userID, since)}
+	}
+}
+
+// flushReplay queues the replay, then the live events held back meanwhile, skipping any
+// the replay already covered. Runs on the hub goroutine. Nothing reads the client's
+// queue yet, so if both don't fit the client gets resync_required instead of the replay.
+func (h *Handler) flushReplay(r replay) {
+	cl := r.client
+	if _, ok := h.clients[cl]; !ok || !cl.replaying {
+		return
+	}
+
+	seen := r.since
+	for _, payload := range r.payloads {
+		seen = max(seen, payloadSeq(payload))
+	}
+	var live [][]byte
+	for _, payload := range cl.held {
+		if seq := payloadSeq(payload); seq == 0 || seq > seen {
+			live = append(live, payload)
+		}
+	}
+	cl.replaying, cl.held = false, nil
+
+	payloads := r.payloads
+	if len(payloads)+len(live) > cap(cl.send) {
+		payloads = [][]byte{resyncRequired(r.since, seen)}
+	}
+	for _, payload := range append(payloads, live...) {
+		h.deliver(cl, payload)
+	}
+}
+
+func resyncRequired(since, current int64) []byte {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"event":  "resync_required",
+		"since":  since,
+		"latest": current,
+	})
+	return payload
+}
@@ -0,0 +1,130 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A reconnecting client gets its missed events before any live one, in seq order
+func TestReplayPrecedesLiveEvents(t *testing.T) {
+	client := testRedis(t)
+
+	h := NewHandler([]string{"*"}, client, nil, nil)
+	go h.Run()
+
+	userID := uuid.New()
+	t.Cleanup(func() {
+		client.Del(context.Background(), seqKey(userID), eventLogKey(userID))
+	})
+
+	// Seqs 1-3 go out while the user is offline
+	for i := 0; i < 3; i++ {
+		h.SendToUsers(map[string]string{"event": "missed"}, userID)
+	}
+	waitIdle(t, h)
+
+	// Live events keep coming while the client reconnects having seen seq 1
+	stop := make(chan struct{})
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				h.SendToUsers(map[string]string{"event": "live"}, userID)
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+
+	cl := newClient(h, nil, userID, uuid.New(), false)
+	h.attach(cl, 1)
+	time.Sleep(20 * time.Millisecond)
+	close(stop)
+	<-published
+
+	// Every seq after 1 exactly once and in order: no gap, no duplicate, and no live
+	// event ahead of the replay
+	var last int64 = 1
+	for received := 0; received < 4; {
+		select {
+		case msg := <-cl.send:
+			var event struct {
+				Seq   int64  `json:"seq"`
+				Event string `json:"event"`
+			}
+			if err := json.Unmarshal(msg, &event); err != nil {
+				t.Fatalf("decode %s: %v", msg, err)
+			}
+			if event.Seq != last+1 {
+				t.Fatalf("want seq %d, got %d (%s)", last+1, event.Seq, msg)
+			}
+			if event.Seq <= 3 && event.Event != "missed" {
+				t.Fatalf("live event %s arrived before the replay finished", msg)
+			}
+			last = event.Seq
+			received++
+		case <-time.After(2 * time.Second):
+			t.Fatalf("seq %d not delivered", last+1)
+		}
+	}
+}
+
+// Live events that reach the hub while a reconnecting client's replay is being read wait
+// for the replay, and those the replay already contains are not sent again
+func TestReplayHoldsBackLiveEventsAndSkipsDuplicates(t *testing.T) {
+	h := newTestHub(t)
+	userID := uuid.New()
+	stamped := func(seq int) []byte {
+		return []byte(fmt.Sprintf(`{"seq":%d,"event":"e%d"}`, seq, seq))
+	}
+
+	cl := newClient(h, nil, userID, uuid.New(), false)
+	h.register <- registration{client: cl, since: 1}
+
+	// Seq 3 was recorded before the log was read and is also in the replay; 4 is newer
+	h.enqueue(envelope{Payload: stamped(3), UserIDs: []uuid.UUID{userID}})
+	h.enqueue(envelope{Payload: stamped(4), UserIDs: []uuid.UUID{userID}})
+	waitIdle(t, h)
+	select {
+	case msg := <-cl.send:
+		t.Fatalf("live event %s delivered before the replay", msg)
+	default:
+	}
+
+	h.replays <- replay{client: cl, since: 1, payloads: [][]byte{stamped(2), stamped(3)}}
+
+	for want := int64(2); want <= 4; want++ {
+		select {
+		case msg := <-cl.send:
+			if seq := payloadSeq(msg); seq != want {
+				t.Fatalf("want seq %d, got %s", want, msg)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("seq %d not delivered", want)
+		}
+	}
+	select {
+	case msg := <-cl.send:
+		t.Fatalf("unexpected extra message %s", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Once the replay is flushed, events go straight through again
+	h.enqueue(envelope{Payload: stamped(5), UserIDs: []uuid.UUID{userID}})
+	select {
+	case msg := <-cl.send:
+		if payloadSeq(msg) != 5 {
+			t.Fatalf("want seq 5, got %s", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("live event after the replay not delivered")
+	}
+}
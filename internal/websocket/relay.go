@@ -0,0 +1,67 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// All instances publish and listen on this channel
+const relayChannel = "ws:events"
+
+// How long publishing to Redis may take before the event stays local-only
+const relayTimeout = 2 * time.Second
+
+// relay publishes a locally produced envelope for the other instances
+func (h *Handler) relay(env envelope) {
+	if h.redis == nil {
+		return
+	}
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		log.Println("WS Relay Encode Error:", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), relayTimeout)
+	defer cancel()
+	if err := h.redis.Publish(ctx, relayChannel, data).Err(); err != nil {
+		log.Println("WS Relay Publish Error:", err)
+	}
+}
+
+// Relay delivers events published by other instances to this instance's sockets until
+// ctx is cancelled. Events this instance published were already delivered locally and
+// are skipped. go-redis re-subscribes on its own after a connection drop.
+func (h *Handler) Relay(ctx context.Context) {
+	if h.redis == nil {
+		return
+	}
+
+	pubsub := h.redis.Subscribe(ctx, relayChannel)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			var env envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+				log.Println("WS Relay Decode Error:", err)
+				continue
+			}
+			if env.Origin == h.instanceID {
+				continue
+			}
+			h.enqueue(env)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package websocket
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// testRedis connects to TEST_REDIS_ADDR (default localhost:6379) or skips the test
+func testRedis(t *testing.T) *redis.Client {
+	t.Helper()
+
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		t.Skipf("Redis not reachable at %s: %v", addr, err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// waitForSubscribers waits until n more listeners than before are on the relay channel
+func waitForSubscribers(t *testing.T, client *redis.Client, before int64, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		counts, err := client.PubSubNumSub(context.Background(), relayChannel).Result()
+		if err == nil && counts[relayChannel] >= before+n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("relay subscribers did not come up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// expectOnce asserts that exactly one message containing want arrives on the client
+func expectOnce(t *testing.T, name string, cl *client, want string) {
+	t.Helper()
+	select {
+	case msg := <-cl.send:
+		if !strings.Contains(string(msg), want) {
+			t.Fatalf("%s: unexpected message %s", name, msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("%s: event not delivered", name)
+	}
+	select {
+	case msg := <-cl.send:
+		t.Fatalf("%s: event delivered twice, second copy %s", name, msg)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestRelayDeliversAcrossHubsExactlyOnce(t *testing.T) {
+	client := testRedis(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	counts, err := client.PubSubNumSub(ctx, relayChannel).Result()
+	if err != nil {
+		t.Fatalf("numsub: %v", err)
+	}
+
+	hubA := NewHandler([]string{"*"}, client, nil, nil)
+	hubB := NewHandler([]string{"*"}, client, nil, nil)
+	for _, h := range []*Handler{hubA, hubB} {
+		go h.Run()
+		go h.Relay(ctx)
+	}
+	waitForSubscribers(t, client, counts[relayChannel], 2)
+
+	userID := uuid.New()
+	t.Cleanup(func() {
+		client.Del(context.Background(), seqKey(userID), eventLogKey(userID))
+	})
+
+	onA := queueClient(hubA, userID)
+	onB := queueClient(hubB, userID)
+
+	hubA.SendToUsers(map[string]string{"event": "relay_test"}, userID)
+
+	// B only hears it through Redis; A delivered locally and must skip its own relay copy
+	expectOnce(t, "client on hub B", onB, `"relay_test"`)
+	expectOnce(t, "client on hub A", onA, `"relay_test"`)
+}
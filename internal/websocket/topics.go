@@ -0,0 +1,95 @@
+package websocket
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Topics a single connection may follow at once
+const maxSubscriptions = 50
+
+// SlotTopic names the live-availability feed of one provider on one (provider-local) date
+func SlotTopic(providerID uuid.UUID, date string) string {
+	return "slots:" + providerID.String() + ":" + date
+}
+
+// slotTopicPrefix matches every date of a provider
+func slotTopicPrefix(providerID uuid.UUID) string {
+	return "slots:" + providerID.String() + ":"
+}
+
+// clientMessage is what a client may send over the socket:
+//
+//	{"action": "subscribe", "provider_id": "...", "date": "2025-10-30"}
+type clientMessage struct {
+	Action     string `json:"action"` // "subscribe" or "unsubscribe"
+	ProviderID string `json:"provider_id"`
+	Date       string `json:"date"`
+}
+
+// subscription asks the hub to add or drop a topic for a client
+type subscription struct {
+	client *client
+	topic  string
+	on     bool
+	reply  map[string]interface{}
+}
+
+// parseSubscription validates a client message. On bad input it returns a subscription
+// without a topic whose reply explains the problem.
+func parseSubscription(cl *client, data []byte) subscription {
+	var msg clientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return subscriptionError(cl, "invalid message")
+	}
+
+	var on bool
+	switch msg.Action {
+	case "subscribe":
+		on = true
+	case "unsubscribe":
+		on = false
+	default:
+		return subscriptionError(cl, "unknown action")
+	}
+
+	providerID, err := uuid.Parse(msg.ProviderID)
+	if err != nil {
+		return subscriptionError(cl, "invalid provider ID")
+	}
+	if _, err := time.Parse("2006-01-02", msg.Date); err != nil {
+		return subscriptionError(cl, "invalid date format (use YYYY-MM-DD)")
+	}
+
+	event := "unsubscribed"
+	if on {
+		event = "subscribed"
+	}
+	return subscription{
+		client: cl,
+		topic:  SlotTopic(providerID, msg.Date),
+		on:     on,
+		reply: map[string]interface{}{
+			"event":       event,
+			"provider_id": providerID,
+			"date":        msg.Date,
+		},
+	}
+}
+
+func subscriptionError(cl *client, message string) subscription {
+	return subscription{client: cl, reply: map[string]interface{}{"event": "error", "error": message}}
+}
+
+// followsPrefix reports whether the client follows any topic starting with prefix
+func (c *client) followsPrefix(prefix string) bool {
+	for topic := range c.topics {
+		if strings.HasPrefix(topic, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,76 +1,406 @@
 package websocket
 
 import (
+	"appointment-booking/internal/domain"
+	"appointment-booking/internal/repository"
+	"appointment-booking/pkg/utils"
+	"encoding/json"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
 )
 
-// Upgrader config
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all for dev
-	},
+// Events waiting for the hub loop
+const broadcastQueueSize = 256
+
+// How long a connect ticket stays valid; the client uses it right away
+const ticketTTL = 30 * time.Second
+
+// envelope is an encoded message plus who may receive it. It is also the wire format
+// between instances, see relay.go.
+type envelope struct {
+	Origin      string          `json:"origin"` // Instance that published it
+	Payload     json.RawMessage `json:"payload"`
+	UserIDs     []uuid.UUID     `json:"user_ids,omitempty"`
+	ToAdmins    bool            `json:"to_admins,omitempty"`
+	SkipUserIDs []uuid.UUID     `json:"skip_user_ids,omitempty"` // Admins already reached through UserIDs
+	Topics      []string        `json:"topics,omitempty"`
+	TopicPrefix string          `json:"topic_prefix,omitempty"`
+
+	// Not an event: hang up every connection of these sessions
+	CloseSessions []uuid.UUID `json:"close_sessions,omitempty"`
 }
 
+// Handler is the hub. The clients map is owned by the Run goroutine; connections
+// join and leave through the register/unregister channels.
 type Handler struct {
-	clients   map[*websocket.Conn]bool
-	broadcast chan interface{}
+	upgrader   websocket.Upgrader
+	clients    map[*client]bool
+	register   chan registration
+	replays    chan replay
+	unregister chan *client
+	broadcast  chan envelope
+
+	subscriptions chan subscription
+
+	// Optional cross-instance fan-out
+	redis      *redis.Client
+	instanceID string
+
+	revocations *repository.RevocationRepository
+	tickets     *repository.WSTicketRepository
+
+	// See userLock in event_log.go
+	userLocks [64]sync.Mutex
+
+	// Heartbeat timing, see client.go
+	pongWait   time.Duration
+	pingPeriod time.Duration
 }
 
-func NewHandler() *Handler {
-	return &Handler{
-		clients:   make(map[*websocket.Conn]bool),
-		broadcast: make(chan interface{}),
+// NewHandler only accepts browser connections from allowedOrigins ("*" allows any).
+// Requests without an Origin header come from non-browser clients and are allowed.
+// With a Redis client, events are also relayed to every other instance (see Relay);
+// pass nil to run single-instance. Tokens of revoked sessions are refused at the handshake.
+func NewHandler(allowedOrigins []string, redis *redis.Client, revocations *repository.RevocationRepository, tickets *repository.WSTicketRepository) *Handler {
+	h := &Handler{
+		clients:    make(map[*client]bool),
+		register:   make(chan registration),
+		replays:    make(chan replay),
+		unregister: make(chan *client),
+		broadcast:  make(chan envelope, broadcastQueueSize),
+
+		subscriptions: make(chan subscription),
+
+		redis:      redis,
+		instanceID: uuid.NewString(),
+
+		revocations: revocations,
+		tickets:     tickets,
+
+		pongWait:   defaultPongWait,
+		pingPeriod: defaultPingPeriod,
+	}
+	h.upgrader = websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" || slices.Contains(allowedOrigins, "*") {
+				return true
+			}
+			return slices.Contains(allowedOrigins, origin)
+		},
+	}
+	return h
+}
+
+// IssueTicket hands the authenticated session a single-use connect ticket for
+// GET /ws?ticket=. Browsers can't set headers on a WebSocket handshake, and an access
+// token in the URL would end up in access logs.
+func (h *Handler) IssueTicket(c *gin.Context) {
+	ticket := &repository.WSTicket{
+		UserID:    c.MustGet("userID").(uuid.UUID),
+		SessionID: c.MustGet("sessionID").(uuid.UUID),
+		Roles:     c.MustGet("roles").(domain.UserRoles),
+	}
+	id, err := h.tickets.Create(c.Request.Context(), ticket, ticketTTL)
+	if err != nil {
+		log.Println("WS ticket error:", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to issue connect ticket"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"ticket": id, "expires_in": int(ticketTTL.Seconds())})
+}
+
+// authenticate resolves the handshake to a session: a connect ticket from ?ticket=, or
+// an access token in the Authorization header for clients that can set headers
+func (h *Handler) authenticate(c *gin.Context) (*repository.WSTicket, bool) {
+	if id := c.Query("ticket"); id != "" {
+		ticket, err := h.tickets.Consume(c.Request.Context(), id)
+		if err == redis.Nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired ticket"})
+			return nil, false
+		}
+		if err != nil {
+			log.Println("WS ticket lookup failed:", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify ticket"})
+			return nil, false
+		}
+		return ticket, true
+	}
+
+	auth := c.GetHeader("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization required"})
+		return nil, false
+	}
+	claims, err := utils.ValidateToken(strings.TrimPrefix(auth, "Bearer "))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return nil, false
 	}
+	return &repository.WSTicket{
+		UserID:    claims.UserID,
+		SessionID: claims.SessionID,
+		Roles:     domain.RolesFromNames(claims.Roles),
+	}, true
 }
 
-// HandleConnection upgrades HTTP to WS
+// HandleConnection authenticates the handshake (see authenticate) and upgrades HTTP
+// to WS. A reconnecting client passes ?since=<last seq it saw> to get the events it missed.
 func (h *Handler) HandleConnection(c *gin.Context) {
-	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	session, ok := h.authenticate(c)
+	if !ok {
+		return
+	}
+
+	// The session may have been revoked after the ticket was issued
+	revoked, err := h.revocations.IsRevoked(c.Request.Context(), session.SessionID)
 	if err != nil {
-		log.Println("WS Upgrade Error:", err)
+		log.Println("WS revocation check failed:", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify session"})
 		return
 	}
-	// defer ws.Close() // Keep connection open
-
-	h.clients[ws] = true
-
-	// Keep-alive loop
-	// If this loop breaks (connection closed), remove client
-	go func() {
-		defer func() {
-			ws.Close()
-			delete(h.clients, ws)
-		}()
-		for {
-			var msg interface{}
-			if err := ws.ReadJSON(&msg); err != nil {
-				break
-			}
+	if revoked {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		return
+	}
+
+	var since int64 = -1
+	if value := c.Query("since"); value != "" {
+		since, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || since < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since sequence"})
+			return
 		}
-	}()
+	}
+
+	ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Println("WS Upgrade Error:", err)
+		return
+	}
+
+	seesAll := session.Roles.Can(domain.PermReadAllAppointments)
+	cl := newClient(h, ws, session.UserID, session.SessionID, seesAll)
+	h.attach(cl, since)
+
+	go cl.writePump()
+	go cl.readPump()
+
+	// A revocation between the check above and the registration would have missed this
+	// connection, so look again now that it is registered
+	if revoked, err := h.revocations.IsRevoked(c.Request.Context(), session.SessionID); err == nil && revoked {
+		h.CloseSessions(session.SessionID)
+	}
 }
 
+// Run is the hub loop. It never blocks on a client: a client whose send queue is
+// full is treated as a slow consumer and disconnected.
 func (h *Handler) Run() {
 	for {
-		msg := <-h.broadcast
-		for client := range h.clients {
-			err := client.WriteJSON(msg)
-			if err != nil {
-				log.Println("WS Write Error:", err)
-				client.Close()
-				delete(h.clients, client)
+		select {
+		case reg := <-h.register:
+			h.clients[reg.client] = true
+			reg.client.replaying = reg.since >= 0
+
+		case r := <-h.replays:
+			h.flushReplay(r)
+
+		case cl := <-h.unregister:
+			h.remove(cl)
+
+		case sub := <-h.subscriptions:
+			h.subscribe(sub)
+
+		case env := <-h.broadcast:
+			if len(env.CloseSessions) > 0 {
+				h.closeSessions(env.CloseSessions)
+				continue
+			}
+			for cl := range h.clients {
+				if !env.isFor(cl) {
+					continue
+				}
+				h.send(cl, env.Payload)
 			}
 		}
 	}
 }
 
-func (h *Handler) Broadcast(message interface{}) {
-	// Non-blocking send (optional safety)
-	go func() {
-		h.broadcast <- message
-	}()
-}
\ No newline at end of file
+// send delivers a live event, or holds it back while the client's replay is pending.
+// A client that falls too far behind meanwhile is dropped like any slow consumer.
+func (h *Handler) send(cl *client, payload []byte) {
+	if !cl.replaying {
+		h.deliver(cl, payload)
+		return
+	}
+	if len(cl.held) >= cap(cl.send)-1 { // Leave room for a resync_required, see flushReplay
+		log.Printf("WS client %s too slow, disconnecting", cl.userID)
+		h.remove(cl)
+		return
+	}
+	cl.held = append(cl.held, payload)
+}
+
+// deliver queues payload for the client, dropping the client if it can't keep up
+func (h *Handler) deliver(cl *client, payload []byte) {
+	select {
+	case cl.send <- payload:
+	default:
+		log.Printf("WS client %s too slow, disconnecting", cl.userID)
+		h.remove(cl)
+	}
+}
+
+// subscribe applies a subscription change and acknowledges it to the client
+func (h *Handler) subscribe(sub subscription) {
+	if _, ok := h.clients[sub.client]; !ok {
+		return
+	}
+
+	reply := sub.reply
+	switch {
+	case sub.topic == "":
+		// Invalid request; reply already holds the error
+	case sub.on && !sub.client.topics[sub.topic] && len(sub.client.topics) >= maxSubscriptions:
+		reply = map[string]interface{}{"event": "error", "error": "too many subscriptions"}
+	case sub.on:
+		sub.client.topics[sub.topic] = true
+	default:
+		delete(sub.client.topics, sub.topic)
+	}
+
+	payload, err := json.Marshal(reply)
+	if err != nil {
+		return
+	}
+	h.deliver(sub.client, payload)
+}
+
+// closeSessions drops every client of the sessions; writePump then sends a close frame
+func (h *Handler) closeSessions(sessionIDs []uuid.UUID) {
+	for cl := range h.clients {
+		if slices.Contains(sessionIDs, cl.sessionID) {
+			h.remove(cl)
+		}
+	}
+}
+
+// remove forgets the client and closes its queue, which makes writePump hang up
+func (h *Handler) remove(cl *client) {
+	if _, ok := h.clients[cl]; ok {
+		delete(h.clients, cl)
+		close(cl.send)
+	}
+}
+
+func (e envelope) isFor(cl *client) bool {
+	if e.ToAdmins && cl.seesAll && !slices.Contains(e.SkipUserIDs, cl.userID) {
+		return true
+	}
+	for _, topic := range e.Topics {
+		if cl.topics[topic] {
+			return true
+		}
+	}
+	if e.TopicPrefix != "" && cl.followsPrefix(e.TopicPrefix) {
+		return true
+	}
+	return slices.Contains(e.UserIDs, cl.userID)
+}
+
+// Publish sends an event to the users it concerns (e.g. the customer and provider of an
+// appointment) and to everyone allowed to read all appointments (admins, front desk)
+func (h *Handler) Publish(message interface{}, userIDs ...uuid.UUID) {
+	payload, ok := encode(message)
+	if !ok {
+		return
+	}
+	h.sendToUsers(payload, userIDs)
+	h.dispatch(envelope{Payload: payload, ToAdmins: true, SkipUserIDs: userIDs})
+}
+
+// SendToUsers sends a private message to the given users only
+func (h *Handler) SendToUsers(message interface{}, userIDs ...uuid.UUID) {
+	payload, ok := encode(message)
+	if !ok {
+		return
+	}
+	h.sendToUsers(payload, userIDs)
+}
+
+// PublishTopic sends an event to every client subscribed to one of the topics.
+// Topic events carry no seq; a reconnecting client refetches what it follows.
+func (h *Handler) PublishTopic(message interface{}, topics ...string) {
+	if payload, ok := encode(message); ok {
+		h.dispatch(envelope{Payload: payload, Topics: topics})
+	}
+}
+
+// PublishProvider sends an event to every client following any date of the provider,
+// e.g. after a change to the weekly schedule
+func (h *Handler) PublishProvider(message interface{}, providerID uuid.UUID) {
+	if payload, ok := encode(message); ok {
+		h.dispatch(envelope{Payload: payload, TopicPrefix: slotTopicPrefix(providerID)})
+	}
+}
+
+// CloseSessions disconnects every connection of the sessions, on all instances. Called
+// when the sessions are revoked, so their sockets stop receiving events.
+func (h *Handler) CloseSessions(sessionIDs ...uuid.UUID) {
+	if len(sessionIDs) > 0 {
+		h.dispatch(envelope{CloseSessions: sessionIDs})
+	}
+}
+
+// sendToUsers stamps each user's copy with that user's next seq and logs it for replay
+func (h *Handler) sendToUsers(payload []byte, userIDs []uuid.UUID) {
+	for _, userID := range userIDs {
+		lock := h.userLock(userID)
+		lock.Lock()
+		h.dispatch(envelope{Payload: h.record(userID, payload), UserIDs: []uuid.UUID{userID}})
+		lock.Unlock()
+	}
+}
+
+// dispatch delivers the envelope to local clients and relays it to the other instances
+func (h *Handler) dispatch(env envelope) {
+	env.Origin = h.instanceID
+	h.enqueue(env)
+	h.relay(env)
+}
+
+func encode(message interface{}) ([]byte, bool) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		log.Println("WS Encode Error:", err)
+		return nil, false
+	}
+	return payload, true
+}
+
+// enqueue hands the envelope to the hub loop. If the hub is backed up an event is
+// dropped rather than stalling the request that produced it.
+func (h *Handler) enqueue(env envelope) {
+	if len(env.CloseSessions) > 0 {
+		// Never dropped: a revoked session must not keep its socket
+		h.broadcast <- env
+		return
+	}
+
+	select {
+	case h.broadcast <- env:
+	default:
+		log.Println("WS broadcast queue full, dropping event")
+	}
+}
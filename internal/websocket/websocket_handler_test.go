@@ -0,0 +1,293 @@
+package websocket
+
+import (
+	"appointment-booking/internal/repository"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+)
+
+// These tests are meant to run under the race detector: go test -race ./internal/websocket
+
+func newTestHub(t *testing.T) *Handler {
+	t.Helper()
+	h := NewHandler([]string{"*"}, nil, nil, nil)
+	go h.Run()
+	return h
+}
+
+// queueClient is a registered client without a connection; the test reads its queue
+func queueClient(h *Handler, userID uuid.UUID) *client {
+	cl := newClient(h, nil, userID, uuid.New(), false)
+	h.register <- registration{client: cl, since: -1}
+	return cl
+}
+
+// drain reads the client's queue until the hub closes it and returns how many messages
+// arrived. It fails the test if the queue is still open after timeout.
+func drain(t *testing.T, cl *client, timeout time.Duration) int {
+	t.Helper()
+	deadline := time.After(timeout)
+	n := 0
+	for {
+		select {
+		case _, ok := <-cl.send:
+			if !ok {
+				return n
+			}
+			n++
+		case <-deadline:
+			t.Fatalf("client queue still open after %v (%d messages read)", timeout, n)
+			return n
+		}
+	}
+}
+
+// waitIdle waits until the hub has taken every queued broadcast
+func waitIdle(t *testing.T, h *Handler) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(h.broadcast) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("hub did not drain its broadcast queue")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHubRegisterUnregisterWhileBroadcasting(t *testing.T) {
+	h := newTestHub(t)
+
+	steadyID := uuid.New()
+	steady := queueClient(h, steadyID)
+	received := make(chan [][]byte, 1)
+	go func() {
+		var got [][]byte
+		for msg := range steady.send {
+			got = append(got, msg)
+		}
+		received <- got
+	}()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	// Publishers of every kind
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				h.Publish(map[string]string{"event": "noise"}, uuid.New())
+				h.SendToUsers(map[string]string{"event": "noise"}, steadyID)
+				h.PublishTopic(map[string]string{"event": "noise"}, "slots:x:2026-01-01")
+				time.Sleep(50 * time.Microsecond)
+			}
+		}()
+	}
+
+	// Clients coming and going
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				cl := queueClient(h, uuid.New())
+				done := make(chan struct{})
+				go func() {
+					for range cl.send {
+					}
+					close(done)
+				}()
+				h.unregister <- cl
+				<-done
+			}
+		}()
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+	waitIdle(t, h)
+
+	h.SendToUsers(map[string]string{"event": "last"}, steadyID)
+	waitIdle(t, h)
+	h.unregister <- steady
+
+	select {
+	case got := <-received:
+		if len(got) == 0 || !strings.Contains(string(got[len(got)-1]), `"last"`) {
+			t.Fatalf("steady client did not get the final event; got %d messages", len(got))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("steady client queue was never closed")
+	}
+}
+
+func TestHubEvictsSlowConsumer(t *testing.T) {
+	h := newTestHub(t)
+
+	slowID := uuid.New()
+	slow := queueClient(h, slowID) // Nobody reads its queue
+
+	for i := 0; i < sendQueueSize+1; i++ {
+		h.SendToUsers(map[string]int{"n": i}, slowID)
+	}
+	waitIdle(t, h)
+
+	// The queue filled up, the next event overflowed it and the hub closed it
+	if n := drain(t, slow, 2*time.Second); n != sendQueueSize {
+		t.Fatalf("want %d queued messages before eviction, got %d", sendQueueSize, n)
+	}
+
+	// The hub keeps serving everyone else
+	okID := uuid.New()
+	ok := queueClient(h, okID)
+	h.SendToUsers(map[string]string{"event": "hello"}, okID)
+	select {
+	case msg := <-ok.send:
+		if !strings.Contains(string(msg), "hello") {
+			t.Fatalf("unexpected message %s", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("hub stopped delivering after evicting a slow consumer")
+	}
+}
+
+func TestHubClosesRevokedSessions(t *testing.T) {
+	h := newTestHub(t)
+
+	userID := uuid.New()
+	revoked := newClient(h, nil, userID, uuid.New(), false)
+	h.register <- registration{client: revoked, since: -1}
+	other := newClient(h, nil, userID, uuid.New(), false) // Same user, another device
+	h.register <- registration{client: other, since: -1}
+
+	h.CloseSessions(revoked.sessionID)
+
+	if n := drain(t, revoked, 2*time.Second); n != 0 {
+		t.Fatalf("revoked session got %d messages", n)
+	}
+
+	h.SendToUsers(map[string]string{"event": "still_here"}, userID)
+	select {
+	case msg, ok := <-other.send:
+		if !ok || !strings.Contains(string(msg), "still_here") {
+			t.Fatalf("other session lost its connection (ok=%v, msg=%s)", ok, msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("other session got no event")
+	}
+}
+
+// serveHub accepts WebSocket connections for userID on a test server, bypassing the
+// JWT handshake
+func serveHub(t *testing.T, h *Handler, userID uuid.UUID) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := h.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		cl := newClient(h, ws, userID, uuid.New(), false)
+		h.register <- registration{client: cl, since: -1}
+		go cl.writePump()
+		go cl.readPump()
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+// readUntilClosed reads (and so processes control frames) until the connection ends
+func readUntilClosed(conn *websocket.Conn) <-chan struct{} {
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	return closed
+}
+
+func TestHeartbeatTimeoutClosesConnection(t *testing.T) {
+	h := NewHandler([]string{"*"}, nil, nil, nil)
+	h.pongWait = 200 * time.Millisecond
+	h.pingPeriod = 50 * time.Millisecond
+	go h.Run()
+	url := serveHub(t, h, uuid.New())
+
+	// A peer that answers pings stays connected well past pongWait
+	alive, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer alive.Close()
+	aliveClosed := readUntilClosed(alive)
+
+	// A peer that never answers is dropped once pongWait passes
+	silent, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer silent.Close()
+	silent.SetPingHandler(func(string) error { return nil })
+	silentClosed := readUntilClosed(silent)
+
+	select {
+	case <-silentClosed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection without pongs was not closed")
+	}
+
+	select {
+	case <-aliveClosed:
+		t.Fatal("connection answering pings was closed")
+	case <-time.After(3 * h.pongWait):
+	}
+}
+
+func TestHandshakeRefusesTokenInQuery(t *testing.T) {
+	h := newTestHub(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws", h.HandleConnection)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws?token=some.access.token", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("want 401 for ?token=, got %d", rec.Code)
+	}
+}
+
+func TestConnectTicketWorksOnce(t *testing.T) {
+	tickets := repository.NewWSTicketRepository(testRedis(t))
+	ctx := context.Background()
+
+	id, err := tickets.Create(ctx, &repository.WSTicket{UserID: uuid.New(), SessionID: uuid.New()}, time.Minute)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tickets.Consume(ctx, id); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if _, err := tickets.Consume(ctx, id); err != redis.Nil {
+		t.Fatalf("second use: want redis.Nil, got %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package notifier
+
+import (
+	"context"
+	"log"
+)
+
+// LogNotifier only writes messages to the log. Used when no mail server is configured.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Send(ctx context.Context, msg Message) error {
+	if msg.Sensitive {
+		log.Printf("📧 [Email] To: %s | Subject: %s | Notification: %s | Body withheld", msg.To, msg.Subject, msg.ID)
+		return nil
+	}
+	log.Printf("📧 [Email] To: %s | Subject: %s | Body: %s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
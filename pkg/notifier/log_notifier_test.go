@@ -0,0 +1,38 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestLogNotifierWithholdsSensitiveBody(t *testing.T) {
+	var out bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&out)
+	defer log.SetOutput(prev)
+
+	err := NewLogNotifier().Send(context.Background(), Message{
+		ID:        "n-1",
+		To:        "ana@example.test",
+		Event:     "password_reset",
+		Subject:   "Reset your password",
+		Body:      "https://app.example/reset-password?token=secret-token",
+		Sensitive: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logged := out.String()
+	if strings.Contains(logged, "secret-token") {
+		t.Fatalf("log contains the reset link: %s", logged)
+	}
+	for _, want := range []string{"ana@example.test", "Reset your password", "n-1"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("log is missing %q: %s", want, logged)
+		}
+	}
+}
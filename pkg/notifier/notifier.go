@@ -0,0 +1,20 @@
+package notifier
+
+import "context"
+
+// Message is a rendered notification ready to go out
+type Message struct {
+	ID      string // Outbox notification ID, for tracing
+	To      string // Address on the channel: email, user ID or webhook URL
+	Event   string
+	Subject string
+	Body    string
+
+	// The body holds a secret (e.g. a password reset link) and must never be logged
+	Sensitive bool
+}
+
+// Notifier defines how we deliver notifications (Email, SMS, logs, ...)
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
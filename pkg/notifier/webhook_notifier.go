@@ -0,0 +1,134 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrWebhookAddressNotAllowed is returned for webhook hosts that resolve to loopback,
+// private, link-local or otherwise internal addresses
+var ErrWebhookAddressNotAllowed = errors.New("webhook host resolves to a non-public address")
+
+// Ranges netip has no predicate for: "this network" and carrier-grade NAT
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// WebhookNotifier POSTs the message as JSON to the URL in Message.To. The URLs come from
+// users, so only https is allowed and every connection is checked to go to a public
+// address; checking at dial time also covers DNS changes and redirects after validation.
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+func NewWebhookNotifier() *WebhookNotifier {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !isPublicAddr(addr) {
+				return ErrWebhookAddressNotAllowed
+			}
+			return nil
+		},
+	}
+
+	return &WebhookNotifier{client: &http.Client{
+		Transport: &http.Transport{
+			Proxy:               nil, // A proxy would hide the real destination from the dial check
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}}
+}
+
+type webhookPayload struct {
+	Event   string `json:"event"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, msg Message) error {
+	if _, err := parseWebhookURL(msg.To); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(webhookPayload{Event: msg.Event, Subject: msg.Subject, Body: msg.Body})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.To, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// ValidateWebhookURL checks a user-supplied webhook URL before it is saved: it must be
+// https and its host must only resolve to public addresses
+func ValidateWebhookURL(ctx context.Context, raw string) error {
+	u, err := parseWebhookURL(raw)
+	if err != nil {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("webhook host does not resolve: %w", err)
+	}
+	for _, addr := range addrs {
+		if !isPublicAddr(addr) {
+			return ErrWebhookAddressNotAllowed
+		}
+	}
+	return nil
+}
+
+func parseWebhookURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return nil, errors.New("webhook URL must be an https URL without credentials")
+	}
+	return u, nil
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ActionClaims authorize one account action, such as verifying an email address or
+// resetting a password. The token ID names the server-side record that makes it single-use.
+type ActionClaims struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Purpose string    `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// GenerateActionToken signs an action token for purpose with the current signing key.
+// Access tokens need a session and action tokens a purpose, so neither passes for the other.
+func GenerateActionToken(purpose string, userID, tokenID uuid.UUID, ttl time.Duration) (string, error) {
+	if keys == nil {
+		return "", errors.New("token keys not configured")
+	}
+
+	claims := ActionClaims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID.String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(keys.signing.Method, claims)
+	token.Header["kid"] = keys.signing.ID
+	return token.SignedString(keys.signing.sign)
+}
+
+// ValidateActionToken checks the signature and expiry and that the token was issued for purpose
+func ValidateActionToken(tokenString, purpose string) (*ActionClaims, error) {
+	if keys == nil {
+		return nil, errors.New("token keys not configured")
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &ActionClaims{}, keys.verificationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*ActionClaims)
+	if !ok || !token.Valid || claims.Purpose != purpose || claims.UserID == uuid.Nil {
+		return nil, errors.New("invalid token")
+	}
+	if _, err := uuid.Parse(claims.ID); err != nil {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
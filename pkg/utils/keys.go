@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Minimum sizes accepted for signing material
+const (
+	minHMACSecretBytes = 32
+	minRSAKeyBits      = 2048
+)
+
+// Key is one entry of the key set, identified in token headers by its kid.
+// A key loaded from a public key file can only verify.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	sign   interface{} // nil for verify-only keys
+	verify interface{}
+}
+
+func (k *Key) CanSign() bool {
+	return k.sign != nil
+}
+
+// HMACKey is a shared HS256 secret. It can never be published in the JWKS.
+func HMACKey(id string, secret []byte) (*Key, error) {
+	if len(secret) < minHMACSecretBytes {
+		return nil, fmt.Errorf("key %q: HMAC secret must be at least %d bytes", id, minHMACSecretBytes)
+	}
+	return &Key{ID: id, Method: jwt.SigningMethodHS256, sign: secret, verify: secret}, nil
+}
+
+// LoadPEMKey reads an RSA (RS256) or Ed25519 (EdDSA) key from a PEM file. Private keys
+// (PKCS#8, or PKCS#1 for RSA) sign and verify; public keys (PKIX) only verify.
+func LoadPEMKey(id, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %q: %s is not PEM encoded", id, path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %q: unsupported PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("key %q: RSA keys must be at least %d bits", id, minRSAKeyBits)
+		}
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, sign: k, verify: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("key %q: RSA keys must be at least %d bits", id, minRSAKeyBits)
+		}
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, verify: k}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: id, Method: jwt.SigningMethodEdDSA, sign: k, verify: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: id, Method: jwt.SigningMethodEdDSA, verify: k}, nil
+	default:
+		return nil, fmt.Errorf("key %q: only RSA and Ed25519 keys are supported", id)
+	}
+}
+
+// KeySet signs with one key and verifies with all of them, so a key can be rotated out
+// by switching the signing key first and removing the old one once its tokens expired
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+func NewKeySet(signingKeyID string, keys ...*Key) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*Key)}
+	for _, k := range keys {
+		if _, ok := ks.keys[k.ID]; ok {
+			return nil, fmt.Errorf("duplicate key ID %q", k.ID)
+		}
+		ks.keys[k.ID] = k
+	}
+
+	signing, ok := ks.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q is not configured", signingKeyID)
+	}
+	if !signing.CanSign() {
+		return nil, fmt.Errorf("signing key %q is a public key", signingKeyID)
+	}
+	ks.signing = signing
+	return ks, nil
+}
+
+// keys is the key set used by GenerateToken and ValidateToken, set once at startup
+var keys *KeySet
+
+// UseKeys installs the key set tokens are signed and verified with
+func UseKeys(ks *KeySet) {
+	keys = ks
+}
+
+// verificationKey picks the key named by the token's kid and rejects tokens whose alg
+// doesn't match it, so e.g. an RSA public key can't be used as an HMAC secret
+func (ks *KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.verify, nil
+}
+
+// JWK is a public key in RFC 7517 form
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS lists the asymmetric verification keys. HMAC secrets are never included,
+// so other services can only verify tokens signed with RS256 or EdDSA keys.
+func PublicJWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	if keys == nil {
+		return set
+	}
+	for _, k := range keys.keys {
+		switch pub := k.verify.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: k.ID,
+				Use: "sig",
+				Alg: k.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: k.ID,
+				Use: "sig",
+				Alg: k.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	slices.SortFunc(set.Keys, func(a, b JWK) int { return strings.Compare(a.Kid, b.Kid) })
+	return set
+}
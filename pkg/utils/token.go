@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -8,41 +12,71 @@ import (
 	"github.com/google/uuid"
 )
 
-// In production, load this from .env
-var jwtSecret = []byte("super_secret_key_change_me_in_prod")
-
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Role   string    `json:"role"`
+	UserID    uuid.UUID `json:"user_id"`
+	Roles     []string  `json:"roles"`
+	SessionID uuid.UUID `json:"sid"` // Login session, revoked as a whole on logout
+	// Unverified accounts may not book; a refresh after verifying picks up the change
+	EmailVerified bool `json:"email_verified"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uuid.UUID, role string) (string, error) {
+// GenerateToken issues a short-lived access token for the session, signed with the
+// current signing key of the key set (see UseKeys)
+func GenerateToken(userID uuid.UUID, roles []string, sessionID uuid.UUID, emailVerified bool, ttl time.Duration) (string, error) {
+	if keys == nil {
+		return "", errors.New("token keys not configured")
+	}
+
 	claims := Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:        userID,
+		Roles:         roles,
+		SessionID:     sessionID,
+		EmailVerified: emailVerified,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // Token valid for 1 day
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	token := jwt.NewWithClaims(keys.signing.Method, claims)
+	token.Header["kid"] = keys.signing.ID
+	return token.SignedString(keys.signing.sign)
 }
 
+// ValidateToken accepts tokens signed by any key of the key set
 func ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+	if keys == nil {
+		return nil, errors.New("token keys not configured")
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keys.verificationKey)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+	// Tokens without a session predate revocation and can't be revoked, so refuse them
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && claims.SessionID != uuid.Nil {
 		return claims, nil
 	}
 
 	return nil, errors.New("invalid token")
 }
+
+// GenerateRefreshToken returns an opaque random refresh token and the hash to store for it
+func GenerateRefreshToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken is the lookup key of a refresh token. The token is random with
+// 256 bits of entropy, so a plain SHA-256 is enough.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}